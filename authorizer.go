@@ -0,0 +1,97 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// ErrNoAuthorizer is returned by DecodeAuthorizer when the request has no authorizer context.
+var ErrNoAuthorizer = errors.New("no authorizer context")
+
+// JWTClaims returns the claims of an Amazon Cognito user pool authorizer stored in ctx.
+func JWTClaims(ctx context.Context) (Claims, bool) {
+	c, ok := RequestContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	m, ok := c.Authorizer["claims"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	claims := make(Claims, len(m))
+	for k, v := range m {
+		claims[k] = claimString(v)
+	}
+
+	return claims, true
+}
+
+// LambdaAuthorizerContext returns the context of a Lambda authorizer stored in ctx,
+// including its "principalId".
+func LambdaAuthorizerContext(ctx context.Context) (map[string]interface{}, bool) {
+	c, ok := RequestContext(ctx)
+	if !ok || len(c.Authorizer) == 0 {
+		return nil, false
+	}
+
+	if _, ok := c.Authorizer["claims"]; ok {
+		return nil, false
+	}
+
+	return c.Authorizer, true
+}
+
+// IAMIdentity returns the caller of a request using the AWS_IAM authorization type,
+// ok is false when the request was not signed.
+func IAMIdentity(ctx context.Context) (events.APIGatewayRequestIdentity, bool) {
+	c, ok := RequestContext(ctx)
+	if !ok || c.Identity.AccessKey == "" {
+		return events.APIGatewayRequestIdentity{}, false
+	}
+
+	return c.Identity, true
+}
+
+// CognitoIdentity returns the caller of a request signed with Amazon Cognito identity
+// pool credentials, ok is false for other requests.
+func CognitoIdentity(ctx context.Context) (events.APIGatewayRequestIdentity, bool) {
+	c, ok := RequestContext(ctx)
+	if !ok || c.Identity.CognitoIdentityID == "" {
+		return events.APIGatewayRequestIdentity{}, false
+	}
+
+	return c.Identity, true
+}
+
+// DecodeAuthorizer decodes the claims of a Cognito user pool authorizer or
+// the context of a Lambda authorizer stored in ctx into v, using encoding/json.
+func DecodeAuthorizer(ctx context.Context, v interface{}) error {
+	if claims, ok := JWTClaims(ctx); ok {
+		return decodeAuthorizer(claims, v)
+	}
+
+	if m, ok := LambdaAuthorizerContext(ctx); ok {
+		return decodeAuthorizer(m, v)
+	}
+
+	return ErrNoAuthorizer
+}
+
+// claimString returns the claim value as a string.
+func claimString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestJWTClaims(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "1234",
+					"exp":            1700000000.0,
+					"cognito:groups": "admin,users",
+				},
+			},
+		},
+	})
+
+	c, ok := JWTClaims(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "1234", c.String("sub"))
+	assert.Equal(t, "1700000000", c.String("exp"))
+	assert.Equal(t, []string{"admin", "users"}, c.Groups())
+
+	_, ok = LambdaAuthorizerContext(ctx)
+	assert.False(t, ok)
+
+	var v struct {
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp,string"`
+	}
+	assert.NoError(t, DecodeAuthorizer(ctx, &v))
+	assert.Equal(t, "1234", v.Sub)
+	assert.Equal(t, int64(1700000000), v.Exp)
+}
+
+func TestLambdaAuthorizerContext(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"principalId": "user-1",
+				"tenant":      "acme",
+				"admin":       true,
+			},
+		},
+	})
+
+	m, ok := LambdaAuthorizerContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", m["tenant"])
+
+	_, ok = JWTClaims(ctx)
+	assert.False(t, ok)
+
+	var v struct {
+		PrincipalID string `json:"principalId"`
+		Admin       bool   `json:"admin"`
+	}
+	assert.NoError(t, DecodeAuthorizer(ctx, &v))
+	assert.Equal(t, "user-1", v.PrincipalID)
+	assert.True(t, v.Admin)
+}
+
+func TestIAMIdentity(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Identity: events.APIGatewayRequestIdentity{
+				AccessKey:             "AKIA",
+				UserArn:               "arn:aws:iam::123456789012:user/tobi",
+				CognitoIdentityID:     "us-east-1:abc",
+				CognitoIdentityPoolID: "us-east-1:pool",
+			},
+		},
+	})
+
+	id, ok := IAMIdentity(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/tobi", id.UserArn)
+
+	id, ok = CognitoIdentity(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "us-east-1:pool", id.CognitoIdentityPoolID)
+}
+
+func TestAuthorizer_none(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayProxyRequest{})
+
+	_, ok := JWTClaims(ctx)
+	assert.False(t, ok)
+
+	_, ok = LambdaAuthorizerContext(ctx)
+	assert.False(t, ok)
+
+	_, ok = IAMIdentity(ctx)
+	assert.False(t, ok)
+
+	_, ok = CognitoIdentity(ctx)
+	assert.False(t, ok)
+
+	assert.Equal(t, ErrNoAuthorizer, DecodeAuthorizer(ctx, &struct{}{}))
+}
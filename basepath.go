@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// WithBasePath strips the base path from request paths, such as "/billing" for APIs
+// mounted at a custom domain base path mapping. Requests outside of the base path are
+// left untouched, the stripped base path is available with BasePath.
+func WithBasePath(base string) Option {
+	return func(c *config) {
+		c.basePath = "/" + strings.Trim(base, "/")
+	}
+}
+
+// WithStageBasePath detects the stage prefix of requests made to the execute-api
+// endpoint, such as "/prod", stripping it from request paths when present.
+func WithStageBasePath() Option {
+	return func(c *config) {
+		c.stageBasePath = true
+	}
+}
+
+// BasePath returns the base path stripped from the request path, if any.
+func BasePath(ctx context.Context) string {
+	v, _ := ctx.Value(basePathContextKey).(string)
+	return v
+}
+
+// AbsoluteURL returns the absolute URL of path as seen by the client, re-adding the base
+// path and the host from the request context's DomainName. Use it for redirects and links
+// so they remain valid behind custom domain mappings and stage prefixes.
+func AbsoluteURL(r *http.Request, path string) string {
+	host := r.Host
+	if c, ok := RequestContext(r.Context()); ok && c.DomainName != "" {
+		host = c.DomainName
+	}
+
+	return "https://" + host + BasePath(r.Context()) + path
+}
+
+// stripBase returns the request with its base path stripped, when configured.
+func (c *config) stripBase(r *http.Request) *http.Request {
+	var base string
+
+	if c.basePath != "" && hasPathPrefix(r.URL.Path, c.basePath) {
+		base = c.basePath
+	} else if c.stageBasePath {
+		if rc, ok := RequestContext(r.Context()); ok {
+			base = stageBase(r.URL.Path, rc.Stage, rc.Path)
+		}
+	}
+
+	if base == "" || base == "/" {
+		return r
+	}
+
+	return withBasePath(r, base)
+}
+
+// stageBase returns the stage base path of the request path, given the stage and
+// the path used by the client.
+func stageBase(path, stage, clientPath string) string {
+	if stage == "" || stage == "$default" {
+		return ""
+	}
+
+	if base := "/" + stage; hasPathPrefix(path, base) {
+		return base
+	}
+
+	// REST APIs omit the stage from the event path but not from the client path
+	if clientPath != path && strings.HasSuffix(clientPath, path) {
+		return strings.TrimSuffix(clientPath, path)
+	}
+
+	return ""
+}
+
+// withBasePath returns the request with the base path stripped from its path and recorded.
+func withBasePath(r *http.Request, base string) *http.Request {
+	r = r.WithContext(context.WithValue(r.Context(), basePathContextKey, base))
+
+	if hasPathPrefix(r.URL.Path, base) {
+		u := *r.URL
+		u.Path = "/" + strings.TrimPrefix(u.Path[len(base):], "/")
+		if hasPathPrefix(u.RawPath, base) {
+			u.RawPath = "/" + strings.TrimPrefix(u.RawPath[len(base):], "/")
+		} else {
+			u.RawPath = ""
+		}
+		r.URL = &u
+		r.RequestURI = u.RequestURI()
+	}
+
+	return r
+}
+
+// hasPathPrefix returns true if the path is the base path or below it.
+func hasPathPrefix(path, base string) bool {
+	return path == base || strings.HasPrefix(path, base+"/")
+}
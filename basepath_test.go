@@ -0,0 +1,132 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestConfig_stripBase(t *testing.T) {
+	cases := []struct {
+		name    string
+		options []Option
+		event   events.APIGatewayProxyRequest
+		path    string
+		uri     string
+		base    string
+	}{
+		{
+			name:    "custom domain",
+			options: []Option{WithBasePath("billing/")},
+			event: events.APIGatewayProxyRequest{
+				Path:                  "/billing/invoices",
+				QueryStringParameters: map[string]string{"page": "2"},
+			},
+			path: "/invoices",
+			uri:  "/invoices?page=2",
+			base: "/billing",
+		},
+		{
+			name:    "custom domain root",
+			options: []Option{WithBasePath("/billing")},
+			event:   events.APIGatewayProxyRequest{Path: "/billing"},
+			path:    "/",
+			uri:     "/",
+			base:    "/billing",
+		},
+		{
+			name:    "outside base path",
+			options: []Option{WithBasePath("/billing")},
+			event:   events.APIGatewayProxyRequest{Path: "/billings"},
+			path:    "/billings",
+			uri:     "/billings",
+		},
+		{
+			name:    "stage prefix",
+			options: []Option{WithStageBasePath()},
+			event: events.APIGatewayProxyRequest{
+				Path: "/pets",
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Stage: "prod",
+					Path:  "/prod/pets",
+				},
+			},
+			path: "/pets",
+			uri:  "/pets",
+			base: "/prod",
+		},
+		{
+			name:    "stage in path",
+			options: []Option{WithStageBasePath()},
+			event: events.APIGatewayProxyRequest{
+				Path: "/prod/pets",
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Stage: "prod",
+					Path:  "/prod/pets",
+				},
+			},
+			path: "/pets",
+			uri:  "/pets",
+			base: "/prod",
+		},
+		{
+			name: "disabled",
+			event: events.APIGatewayProxyRequest{
+				Path: "/prod/pets",
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Stage: "prod",
+				},
+			},
+			path: "/prod/pets",
+			uri:  "/prod/pets",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var conf config
+			for _, o := range c.options {
+				o(&conf)
+			}
+
+			c.event.HTTPMethod = "GET"
+			r, err := NewRequest(context.Background(), c.event)
+			assert.NoError(t, err)
+
+			r = conf.stripBase(r)
+			assert.Equal(t, c.path, r.URL.Path)
+			assert.Equal(t, c.uri, r.RequestURI)
+			assert.Equal(t, c.base, BasePath(r.Context()))
+		})
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/billing/invoices",
+		Headers: map[string]string{
+			"Host": "internal.example.com",
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			DomainName: "api.example.com",
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	var c config
+	WithBasePath("/billing")(&c)
+	r = c.stripBase(r)
+
+	assert.Equal(t, "https://api.example.com/billing/login", AbsoluteURL(r, "/login"))
+
+	r, err = http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+	r.Host = "localhost:3000"
+	assert.Equal(t, "https://localhost:3000/login", AbsoluteURL(r, "/login"))
+}
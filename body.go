@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// setBody sets the request body from the event body, base64 encoded bodies are decoded
+// lazily while reading so large uploads are not copied.
+func setBody(req *http.Request, body string, encoded bool) error {
+	n := int64(len(body))
+
+	if encoded {
+		size, err := base64Len(body)
+		if err != nil {
+			return errors.Wrap(err, "decoding base64 body")
+		}
+		n = size
+	}
+
+	if n == 0 {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		req.ContentLength = 0
+		return nil
+	}
+
+	open := func() (io.ReadCloser, error) {
+		var r io.Reader = strings.NewReader(body)
+		if encoded {
+			r = base64.NewDecoder(base64.StdEncoding, r)
+		}
+		return ioutil.NopCloser(r), nil
+	}
+
+	req.Body, _ = open()
+	req.GetBody = open
+	req.ContentLength = n
+	return nil
+}
+
+// eventBody reads the request body for an event, base64 encoding it
+// when its media type represents binary.
+func eventBody(r *http.Request, m *mediaTypes) (string, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", false, nil
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", false, errors.Wrap(err, "reading body")
+	}
+
+	if len(b) > 0 && m.isBinary(r.Header.Get("Content-Type")) {
+		return base64.StdEncoding.EncodeToString(b), true, nil
+	}
+
+	return string(b), false, nil
+}
+
+// decodeBody returns the bytes of an event body, decoding base64 encoded bodies.
+func decodeBody(body string, encoded bool) ([]byte, error) {
+	if !encoded {
+		return []byte(body), nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding base64 body")
+	}
+
+	return b, nil
+}
+
+// base64Len returns the decoded length of padded standard base64 without decoding it,
+// or an error if s is invalid. CR and LF are ignored, as they are by the decoder.
+func base64Len(s string) (int64, error) {
+	var n, pad int
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\r' || c == '\n':
+			continue
+		case pad > 0 && c != '=':
+			return 0, base64.CorruptInputError(i)
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '+', c == '/':
+		case c == '=' && pad < 2:
+			pad++
+		default:
+			return 0, base64.CorruptInputError(i)
+		}
+		n++
+	}
+
+	if n%4 != 0 {
+		return 0, base64.CorruptInputError(n / 4 * 4)
+	}
+
+	return int64(n/4*3 - pad), nil
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestSetBody(t *testing.T) {
+	for _, s := range []string{"", "a", "ab", "abc", "abcd", "hello world\n"} {
+		t.Run(s, func(t *testing.T) {
+			for _, encoded := range []bool{false, true} {
+				body := s
+				if encoded {
+					body = base64.StdEncoding.EncodeToString([]byte(s))
+				}
+
+				r, err := http.NewRequest("POST", "/", nil)
+				assert.NoError(t, err)
+				assert.NoError(t, setBody(r, body, encoded))
+
+				assert.Equal(t, int64(len(s)), r.ContentLength)
+
+				b, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, s, string(b))
+
+				rc, err := r.GetBody()
+				assert.NoError(t, err)
+				b, err = ioutil.ReadAll(rc)
+				assert.NoError(t, err)
+				assert.Equal(t, s, string(b))
+			}
+		})
+	}
+}
+
+func TestSetBody_newlines(t *testing.T) {
+	for _, body := range []string{"aGVs\nbG8=", "aGVs\r\nbG8=\r\n", "aGVsbG8=\n"} {
+		r, err := http.NewRequest("POST", "/", nil)
+		assert.NoError(t, err)
+		assert.NoError(t, setBody(r, body, true), body)
+
+		assert.Equal(t, int64(5), r.ContentLength)
+
+		b, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(b))
+	}
+}
+
+func TestSetBody_invalid(t *testing.T) {
+	for _, s := range []string{"a", "aGVsbG8=d29y", "aGVs=G8=", "a===", "aGVsbG8#", "aGVsbG8=\n=", "aGV\nsbG8"} {
+		r, err := http.NewRequest("POST", "/", nil)
+		assert.NoError(t, err)
+
+		err = setBody(r, s, true)
+		assert.Error(t, err, s)
+		assert.Contains(t, err.Error(), "decoding base64 body: illegal base64 data")
+	}
+}
+
+// largeBody is a base64 encoded 10 MB upload.
+var largeBody = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("0123456789abcdef"), 10<<20/16))
+
+func BenchmarkNewRequest_largeBody(b *testing.B) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod:      "POST",
+		Path:            "/upload",
+		Body:            largeBody,
+		IsBase64Encoded: true,
+	}
+
+	b.SetBytes(10 << 20)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		r, err := NewRequest(context.Background(), e)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		io.Copy(ioutil.Discard, r.Body)
+	}
+}
+
+// BenchmarkNewRequest_largeBodyEager is the previous decoding of the whole body into strings, for comparison.
+func BenchmarkNewRequest_largeBodyEager(b *testing.B) {
+	b.SetBytes(10 << 20)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		d, err := base64.StdEncoding.DecodeString(largeBody)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		io.Copy(ioutil.Discard, strings.NewReader(string(d)))
+	}
+}
@@ -0,0 +1,90 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Claims are the claims of a JWT or Amazon Cognito user pool authorizer, API Gateway
+// passes claims as strings so numbers and lists are parsed by the helper methods.
+type Claims map[string]string
+
+// String returns the claim, or an empty string.
+func (c Claims) String(name string) string {
+	return c[name]
+}
+
+// Number returns the claim parsed as a number, ok is false when it is missing or not a number.
+func (c Claims) Number(name string) (float64, bool) {
+	n, err := strconv.ParseFloat(c[name], 64)
+	return n, err == nil
+}
+
+// Strings returns the claim parsed as a list, accepting JSON arrays, the "[a b]" form
+// used by HTTP APIs, and comma or space separated values.
+func (c Claims) Strings(name string) []string {
+	s := strings.TrimSpace(c[name])
+	if s == "" {
+		return nil
+	}
+
+	var values []string
+	if strings.HasPrefix(s, "[\"") && json.Unmarshal([]byte(s), &values) == nil {
+		return values
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// Scopes returns the OAuth scopes of the "scope" or "scp" claim.
+func (c Claims) Scopes() []string {
+	if _, ok := c["scope"]; ok {
+		return c.Strings("scope")
+	}
+
+	return c.Strings("scp")
+}
+
+// HasScope returns true if the claims grant the OAuth scope.
+func (c Claims) HasScope(scope string) bool {
+	return contains(c.Scopes(), scope)
+}
+
+// Groups returns the groups of the "cognito:groups" or "groups" claim.
+func (c Claims) Groups() []string {
+	if _, ok := c["cognito:groups"]; ok {
+		return c.Strings("cognito:groups")
+	}
+
+	return c.Strings("groups")
+}
+
+// InGroup returns true if the claims include the group.
+func (c Claims) InGroup(group string) bool {
+	return contains(c.Groups(), group)
+}
+
+// contains returns true if values contains s.
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeAuthorizer decodes the authorizer context into v.
+func decodeAuthorizer(context interface{}, v interface{}) error {
+	b, err := json.Marshal(context)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
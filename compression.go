@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Encoder returns a writer compressing to w.
+type Encoder func(w io.Writer) (io.WriteCloser, error)
+
+// Compression configures response compression.
+//
+// Only gzip and deflate are built-in, brotli ("br") and zstd are preferred when
+// the client accepts them but require an Encoder supplied in Encoders, without
+// one clients accepting only those codings receive uncompressed responses.
+type Compression struct {
+	// MinSize is the minimum body size in bytes to compress, defaults to 1024.
+	MinSize int
+
+	// ContentTypes are the media type patterns to compress, such as "text/*" or "+json",
+	// defaults to the media types sent as text.
+	ContentTypes []string
+
+	// Encoders are additional content codings keyed by name, such as "br".
+	Encoders map[string]Encoder
+}
+
+// encodingPreference is the order used when the client has no preference between codings.
+var encodingPreference = []string{"br", "zstd", "gzip", "deflate"}
+
+// compression is the resolved compression configuration.
+type compression struct {
+	minSize  int
+	types    mediaTypes
+	encoders map[string]Encoder
+}
+
+// newCompression returns the resolved configuration for c.
+func newCompression(c Compression) *compression {
+	z := &compression{
+		minSize: c.MinSize,
+		encoders: map[string]Encoder{
+			"gzip": func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+			"deflate": func(w io.Writer) (io.WriteCloser, error) {
+				return flate.NewWriter(w, flate.DefaultCompression)
+			},
+		},
+	}
+
+	if z.minSize == 0 {
+		z.minSize = 1024
+	}
+
+	z.types.add(false, c.ContentTypes...)
+
+	for name, e := range c.Encoders {
+		z.encoders[strings.ToLower(name)] = e
+	}
+
+	return z
+}
+
+// compressible returns true if responses with the header may be compressed.
+func (z *compression) compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	if strings.Contains(strings.ToLower(h.Get("Cache-Control")), "no-transform") {
+		return false
+	}
+
+	if len(z.types.types) == 0 {
+		var m *mediaTypes
+		return !m.isBinary(h.Get("Content-Type"))
+	}
+
+	mt, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	_, ok := lookupMediaType(z.types.types, mt)
+	return ok
+}
+
+// negotiate returns the supported content coding preferred by the Accept-Encoding header value.
+func (z *compression) negotiate(accept string) string {
+	qs := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		if name == "*" {
+			wildcard = q
+		} else {
+			qs[name] = q
+		}
+	}
+
+	var names []string
+	for name := range z.encoders {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := preference(names[i]), preference(names[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+
+	best, bestQ := "", 0.0
+	for _, name := range names {
+		q, ok := qs[name]
+		if !ok {
+			q = wildcard
+		}
+
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+
+	return best
+}
+
+// preference returns the rank of the content coding, lower is preferred.
+func preference(name string) int {
+	for i, p := range encodingPreference {
+		if p == name {
+			return i
+		}
+	}
+
+	return len(encodingPreference)
+}
+
+// compress compresses the body in place when the response is eligible,
+// returning true if the header was modified.
+func (z *compression) compress(h http.Header, status int, body *bytes.Buffer, accept string) bool {
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	if body.Len() < z.minSize || !z.compressible(h) {
+		return false
+	}
+
+	h.Add("Vary", "Accept-Encoding")
+
+	name := z.negotiate(accept)
+	if name == "" {
+		return true
+	}
+
+	var buf bytes.Buffer
+	cw, err := z.encoders[name](&buf)
+	if err != nil {
+		return true
+	}
+
+	if _, err := cw.Write(body.Bytes()); err != nil {
+		return true
+	}
+
+	if err := cw.Close(); err != nil {
+		return true
+	}
+
+	body.Reset()
+	body.Write(buf.Bytes())
+	h.Set("Content-Encoding", name)
+	h.Del("Content-Length")
+	return true
+}
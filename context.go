@@ -9,12 +9,31 @@ import (
 // key is the type used for any items added to the request context.
 type key int
 
-// requestContextKey is the key for the api gateway proxy `RequestContext`.
-const requestContextKey key = iota
+const (
+	// requestContextKey is the key for the api gateway proxy `RequestContext`.
+	requestContextKey key = iota
+
+	// routeContextKey is the key for the api gateway resource template.
+	routeContextKey
+
+	// stageVariablesContextKey is the key for the api gateway stage variables.
+	stageVariablesContextKey
+
+	// stageConfigContextKey is the key for the stage config bound by WithStageConfig.
+	stageConfigContextKey
+
+	// basePathContextKey is the key for the base path stripped from the request path.
+	basePathContextKey
+
+	// traceContextKey is the key for the x-ray trace header.
+	traceContextKey
+)
 
 // newContext returns a new Context with specific api gateway proxy values.
 func newContext(ctx context.Context, e events.APIGatewayProxyRequest) context.Context {
-	return context.WithValue(ctx, requestContextKey, e.RequestContext)
+	ctx = context.WithValue(ctx, requestContextKey, e.RequestContext)
+	ctx = withStageVariables(ctx, e.StageVariables)
+	return withRoute(ctx, e.Resource)
 }
 
 // RequestContext returns the APIGatewayProxyRequestContext value stored in ctx.
@@ -22,3 +41,33 @@ func RequestContext(ctx context.Context) (events.APIGatewayProxyRequestContext,
 	c, ok := ctx.Value(requestContextKey).(events.APIGatewayProxyRequestContext)
 	return c, ok
 }
+
+// RouteTemplate returns the api gateway resource template stored in ctx, such as "/pets/{id}".
+func RouteTemplate(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(routeContextKey).(string)
+	return v, ok
+}
+
+// withRoute returns a new Context with the route template, if any.
+func withRoute(ctx context.Context, route string) context.Context {
+	if route == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, routeContextKey, route)
+}
+
+// StageVariables returns the api gateway stage variables stored in ctx.
+func StageVariables(ctx context.Context) (map[string]string, bool) {
+	v, ok := ctx.Value(stageVariablesContextKey).(map[string]string)
+	return v, ok
+}
+
+// withStageVariables returns a new Context with the stage variables, if any.
+func withStageVariables(ctx context.Context, vars map[string]string) context.Context {
+	if vars == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, stageVariablesContextKey, vars)
+}
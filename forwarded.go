@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// WithTrustedProxies sets the addresses or CIDR ranges of proxies in front of the API, such
+// as CloudFront, whose X-Forwarded-For entries are skipped when determining the client's
+// address. It panics if an address is invalid.
+func WithTrustedProxies(proxies ...string) Option {
+	var nets []*net.IPNet
+
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				panic("gateway: invalid trusted proxy address " + p)
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			panic("gateway: invalid trusted proxy range " + p)
+		}
+		nets = append(nets, n)
+	}
+
+	return func(c *config) {
+		c.trustedProxies = append(c.trustedProxies, nets...)
+	}
+}
+
+// forwardedHeader returns the request's header fields with the Host and X-Forwarded-*
+// fields added by the service in front of the function, and the client address.
+func forwardedHeader(r *http.Request) (http.Header, string) {
+	h := r.Header.Clone()
+	if h == nil {
+		h = make(http.Header)
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	if host != "" {
+		h.Set("Host", host)
+	}
+
+	// scheme, client requests have no TLS state so the url's scheme is preferred
+	scheme := r.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+
+	h.Set("X-Forwarded-Proto", scheme)
+
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		h.Set("X-Forwarded-Port", port)
+	} else if scheme == "https" {
+		h.Set("X-Forwarded-Port", "443")
+	} else {
+		h.Set("X-Forwarded-Port", "80")
+	}
+
+	// client address, appended to the chain of proxies
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	if clientIP != "" {
+		xff := clientIP
+		if prior := h.Get("X-Forwarded-For"); prior != "" {
+			xff = prior + ", " + clientIP
+		}
+		h.Set("X-Forwarded-For", xff)
+	}
+
+	return h, clientIP
+}
+
+// setConnection sets the scheme, TLS state, protocol and remote address of the request.
+func setConnection(req *http.Request, protocol, remoteIP string) {
+	// scheme
+	scheme := strings.ToLower(req.Header.Get("X-Forwarded-Proto"))
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	if req.URL.Host != "" {
+		req.URL.Scheme = scheme
+	}
+
+	if scheme == "https" {
+		req.TLS = &tls.ConnectionState{
+			HandshakeComplete: true,
+			ServerName:        req.Host,
+		}
+	}
+
+	// protocol
+	if major, minor, ok := http.ParseHTTPVersion(protocol); ok {
+		req.Proto, req.ProtoMajor, req.ProtoMinor = protocol, major, minor
+	}
+
+	// remote addr, the client port is not known
+	if remoteIP != "" {
+		req.RemoteAddr = net.JoinHostPort(remoteIP, "0")
+	}
+}
+
+// forwardedFor returns the request with the client's address as its RemoteAddr,
+// skipping trusted proxies in the X-Forwarded-For chain.
+func (c *config) forwardedFor(r *http.Request) *http.Request {
+	if len(c.trustedProxies) == 0 {
+		return r
+	}
+
+	host, port, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r
+	}
+
+	var chain []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(v, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				chain = append(chain, ip)
+			}
+		}
+	}
+
+	if n := len(chain); n == 0 || chain[n-1] != host {
+		chain = append(chain, host)
+	}
+
+	client := chain[0]
+	for i := len(chain) - 1; i >= 0; i-- {
+		if !c.trusted(chain[i]) {
+			client = chain[i]
+			break
+		}
+	}
+
+	if client != host {
+		r.RemoteAddr = net.JoinHostPort(client, port)
+	}
+
+	return r
+}
+
+// trusted returns true if the address is a trusted proxy.
+func (c *config) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range c.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -30,3 +30,42 @@ func TestGateway_Invoke(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, `{"body":"Hello World from Go\n", "headers":{"Content-Type":"text/plain; charset=utf8"}, "multiValueHeaders":{}, "statusCode":200}`, string(payload))
 }
+
+func TestGateway_Invoke_binaryMediaTypes(t *testing.T) {
+	e := []byte(`{"version": "1.0", "httpMethod": "GET", "path": "/pets/luna"}`)
+
+	gw := gateway.NewGateway(http.HandlerFunc(hello), gateway.WithBinaryMediaTypes("*/*"))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"body":"SGVsbG8gV29ybGQgZnJvbSBHbwo=", "headers":{"Content-Type":"text/plain; charset=utf8"}, "multiValueHeaders":{}, "statusCode":200, "isBase64Encoded":true}`, string(payload))
+}
+
+func TestGateway_Invoke_payloadLimit(t *testing.T) {
+	e := []byte(`{"version": "1.0", "httpMethod": "GET", "path": "/pets/luna"}`)
+
+	gw := gateway.NewGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, gateway.DefaultMaxPayload))
+	}))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `"statusCode":502`)
+	assert.Contains(t, string(payload), `exceeds the Lambda payload limit of 6291456 bytes`)
+}
+
+func TestGateway_Invoke_stageConfig(t *testing.T) {
+	type config struct {
+		Backend string `stage:"backend" default:"https://api.example.com"`
+	}
+
+	e := []byte(`{"httpMethod": "GET", "path": "/pets", "stageVariables": {"backend": "https://beta.example.com"}}`)
+
+	gw := gateway.NewGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, gateway.StageConfig(r.Context()).(*config).Backend)
+	}), gateway.WithStageConfig(&config{}))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `"body":"https://beta.example.com"`)
+}
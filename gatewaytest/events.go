@@ -0,0 +1,80 @@
+package gatewaytest
+
+import (
+	"strings"
+
+	"github.com/apex/gateway"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ProxyEvent returns the event sent by REST APIs, with both the single
+// and multi-value header fields and query string parameters.
+func (r *Request) ProxyEvent() events.APIGatewayProxyRequest {
+	e, err := gateway.EventFromRequest(r.httpRequest())
+	if err != nil {
+		panic("gatewaytest: encoding event: " + err.Error())
+	}
+
+	e.Body, e.IsBase64Encoded = r.encodeBody(e.Body, e.IsBase64Encoded)
+	e.StageVariables = r.stageVariables
+
+	if r.route != "" {
+		e.Resource = r.route
+		e.PathParameters = nil
+	}
+
+	if r.pathParameters != nil {
+		e.PathParameters = r.pathParameters
+	}
+
+	c := &e.RequestContext
+	c.ResourcePath = e.Resource
+	c.AccountID = AccountID
+	c.ResourceID = "abc123"
+	c.APIID = APIID
+	c.RequestID = r.requestID
+	c.Authorizer = r.proxyAuthorizer()
+	c.RequestTime, c.RequestTimeEpoch = r.timestamp()
+
+	if r.stage != "" {
+		c.Stage = r.stage
+	}
+
+	if r.iam != nil {
+		id := &c.Identity
+		id.AccountID = r.iam.accountID
+		id.UserArn = r.iam.userARN
+		id.AccessKey = r.iam.accessKey
+		id.CognitoIdentityPoolID = r.iam.identityPoolID
+		id.CognitoIdentityID = r.iam.identityID
+		if r.iam.identityID != "" {
+			id.CognitoAuthenticationType = "authenticated"
+		}
+	}
+
+	return e
+}
+
+// proxyAuthorizer returns the authorizer context of REST API events.
+func (r *Request) proxyAuthorizer() map[string]interface{} {
+	switch {
+	case r.claims != nil:
+		claims := make(map[string]interface{}, len(r.claims)+1)
+		for k, v := range r.claims {
+			claims[k] = v
+		}
+		if _, ok := claims["scope"]; !ok && len(r.scopes) > 0 {
+			claims["scope"] = strings.Join(r.scopes, " ")
+		}
+		return map[string]interface{}{"claims": claims}
+	case r.lambda != nil || r.principalID != "":
+		m := make(map[string]interface{}, len(r.lambda)+1)
+		for k, v := range r.lambda {
+			m[k] = v
+		}
+		m["principalId"] = r.principalID
+		return m
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,197 @@
+package gatewaytest_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apex/gateway"
+	"github.com/apex/gateway/gatewaytest"
+	"github.com/tj/assert"
+)
+
+func Example() {
+	rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := gateway.JWTClaims(r.Context())
+		fmt.Fprintf(w, "Hello %s", claims.String("sub"))
+	}))
+
+	req := gatewaytest.NewRequest("GET", "/pets").JWT(map[string]string{"sub": "tobi"})
+
+	res, _ := rec.Do(context.Background(), req.ProxyEvent())
+	b, _ := ioutil.ReadAll(res.Body)
+	fmt.Println(res.StatusCode, string(b))
+	// Output: 200 Hello tobi
+}
+
+// echo responds with a summary of the request.
+func echo(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Set("X-Method", r.Method)
+	w.Header().Set("X-URL", r.URL.RequestURI())
+	w.Header().Set("X-Host", r.Host)
+	w.Header().Set("X-Remote-Addr", r.RemoteAddr)
+	w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+func TestRecorder_Do(t *testing.T) {
+	req := gatewaytest.NewRequest("POST", "https://api.example.com/pets?species=ferret&species=cat").
+		Header("Content-Type", "image/png").
+		Body([]byte("\x89PNG")).
+		SourceIP("1.2.3.4")
+
+	for _, e := range []interface{}{req.ProxyEvent(), req} {
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(echo))
+
+		res, err := rec.Do(context.Background(), e)
+		assert.NoError(t, err)
+
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "201 Created", res.Status)
+		assert.Equal(t, "\x89PNG", string(b))
+		assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+		assert.Equal(t, []string{"a=1", "b=2"}, res.Header["Set-Cookie"])
+		assert.Equal(t, "POST", res.Header.Get("X-Method"))
+		assert.Equal(t, "/pets?species=ferret&species=cat", res.Header.Get("X-URL"))
+		assert.Equal(t, "api.example.com", res.Header.Get("X-Host"))
+		assert.Equal(t, "1.2.3.4:0", res.Header.Get("X-Remote-Addr"))
+		assert.Equal(t, gatewaytest.RequestID, res.Header.Get("X-Request-Id"))
+		assert.NotEmpty(t, rec.Event)
+		assert.NotEmpty(t, rec.Payload)
+	}
+}
+
+func TestRecorder_Do_unsupported(t *testing.T) {
+	rec := gatewaytest.NewRecorder(http.HandlerFunc(echo))
+
+	_, err := rec.Do(context.Background(), map[string]string{"path": "/"})
+	assert.EqualError(t, err, "unsupported event type map[string]string")
+}
+
+func TestRequest_authorizers(t *testing.T) {
+	t.Run("jwt", func(t *testing.T) {
+		req := gatewaytest.NewRequest("GET", "/").JWT(map[string]string{"sub": "tobi"}, "pets:read")
+
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := gateway.JWTClaims(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "tobi", claims.String("sub"))
+			assert.True(t, claims.HasScope("pets:read"))
+		}))
+
+		_, err := rec.Do(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("lambda", func(t *testing.T) {
+		req := gatewaytest.NewRequest("GET", "/").LambdaAuthorizer("tobi", map[string]interface{}{"tier": "gold"})
+
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m, ok := gateway.LambdaAuthorizerContext(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "gold", m["tier"])
+			assert.Equal(t, "tobi", m["principalId"])
+		}))
+
+		_, err := rec.Do(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("iam", func(t *testing.T) {
+		req := gatewaytest.NewRequest("GET", "/").
+			IAM(gatewaytest.AccountID, "arn:aws:iam::123456789012:user/tobi", "AKIA").
+			CognitoIdentity("us-east-1:pool", "us-east-1:identity")
+
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok := gateway.IAMIdentity(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "arn:aws:iam::123456789012:user/tobi", id.UserArn)
+
+			c, ok := gateway.CognitoIdentity(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "us-east-1:identity", c.CognitoIdentityID)
+		}))
+
+		_, err := rec.Do(context.Background(), req)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRequest_route(t *testing.T) {
+	req := gatewaytest.NewRequest("GET", "/pets/luna").
+		Route("/pets/{id}").
+		PathParameter("id", "luna").
+		StageVariable("backend", "https://example.com").
+		Stage("beta")
+
+	rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := gateway.RouteTemplate(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "/pets/{id}", route)
+		assert.Equal(t, "luna", r.PathValue("id"))
+		assert.Equal(t, "beta", r.Header.Get("X-Stage"))
+		vars, _ := gateway.StageVariables(r.Context())
+		assert.Equal(t, "https://example.com", vars["backend"])
+	}))
+
+	_, err := rec.Do(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+func TestFromRequest(t *testing.T) {
+	r := httptest.NewRequest("PUT", "http://api.example.com/pets/luna?x=1", strings.NewReader(`{"name":"Luna"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	e := gatewaytest.FromRequest(r).ProxyEvent()
+	assert.Equal(t, "PUT", e.HTTPMethod)
+	assert.Equal(t, "/pets/luna", e.Path)
+	assert.Equal(t, map[string]string{"x": "1"}, e.QueryStringParameters)
+	assert.Equal(t, "api.example.com", e.Headers["Host"])
+	assert.Equal(t, "application/json", e.Headers["Content-Type"])
+	assert.Equal(t, "192.0.2.1", e.RequestContext.Identity.SourceIP)
+	assert.Equal(t, `{"name":"Luna"}`, e.Body)
+	assert.False(t, e.IsBase64Encoded)
+
+	b, err := ioutil.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Luna"}`, string(b))
+}
+
+func TestRequest_JSON(t *testing.T) {
+	e := gatewaytest.NewRequest("POST", "/pets").JSON(map[string]string{"name": "Tobi"}).ProxyEvent()
+	assert.Equal(t, `{"name":"Tobi"}`, e.Body)
+	assert.Equal(t, "application/json", e.Headers["Content-Type"])
+	assert.Equal(t, "15", e.Headers["Content-Length"])
+}
+
+func TestRequest_Binary(t *testing.T) {
+	e := gatewaytest.NewRequest("POST", "/pets").Body([]byte("hello")).Binary().ProxyEvent()
+	assert.Equal(t, "aGVsbG8=", e.Body)
+	assert.True(t, e.IsBase64Encoded)
+}
+
+func TestRequest_defaults(t *testing.T) {
+	e := gatewaytest.NewRequest("GET", "/pets?species=ferret").ProxyEvent()
+	assert.Equal(t, "/{proxy+}", e.Resource)
+	assert.Equal(t, map[string]string{"proxy": "pets"}, e.PathParameters)
+	assert.Equal(t, "$default", e.RequestContext.Stage)
+	assert.Equal(t, "example", e.RequestContext.DomainPrefix)
+	assert.Equal(t, gatewaytest.RequestID, e.RequestContext.RequestID)
+	assert.Equal(t, "192.0.2.1", e.RequestContext.Identity.SourceIP)
+	assert.Equal(t, map[string]string{"species": "ferret"}, e.QueryStringParameters)
+	assert.Equal(t, "https", e.Headers["X-Forwarded-Proto"])
+	assert.Equal(t, "443", e.Headers["X-Forwarded-Port"])
+	assert.Equal(t, "192.0.2.1", e.Headers["X-Forwarded-For"])
+}
@@ -0,0 +1,69 @@
+package gatewaytest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/apex/gateway"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// Invoker is implemented by the gateways.
+type Invoker interface {
+	Invoke(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// Recorder invokes a gateway with events and decodes its responses,
+// recording the payloads exchanged for inspection.
+type Recorder struct {
+	// Gateway is the gateway invoked.
+	Gateway Invoker
+
+	// Event is the payload of the last event sent.
+	Event []byte
+
+	// Payload is the payload of the last response received.
+	Payload []byte
+}
+
+// NewRecorder returns a Recorder invoking a Gateway serving h with the options.
+func NewRecorder(h http.Handler, options ...gateway.Option) *Recorder {
+	return &Recorder{Gateway: gateway.NewGateway(h, options...)}
+}
+
+// Do invokes the gateway with the event and returns the decoded response.
+//
+// The event is an events.APIGatewayProxyRequest, as returned by Request.ProxyEvent,
+// or a *Request which is sent as such.
+func (rec *Recorder) Do(ctx context.Context, event interface{}) (*http.Response, error) {
+	if r, ok := event.(*Request); ok {
+		event = r.ProxyEvent()
+	}
+
+	if _, ok := event.(events.APIGatewayProxyRequest); !ok {
+		return nil, errors.Errorf("unsupported event type %T", event)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding event")
+	}
+
+	rec.Event = payload
+
+	out, err := rec.Gateway.Invoke(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.Payload = out
+
+	var resp events.APIGatewayProxyResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, errors.Wrap(err, "decoding response")
+	}
+
+	return gateway.ResponseFromEvent(resp)
+}
@@ -1,9 +1,16 @@
 module github.com/apex/gateway
 
-go 1.12
+go 1.22
 
 require (
-	github.com/aws/aws-lambda-go v1.17.0
+	github.com/aws/aws-lambda-go v1.47.0
 	github.com/pkg/errors v0.9.1
 	github.com/tj/assert v0.0.3
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/testify v1.7.2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
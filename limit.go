@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxPayload is the Lambda synchronous invocation response payload limit in bytes.
+const DefaultMaxPayload = 6 * 1024 * 1024
+
+// envelopeSlack accounts for the response fields other than the header and body.
+const envelopeSlack = 128
+
+// Overflow is the strategy applied to responses exceeding the payload limit.
+type Overflow int
+
+const (
+	// OverflowError responds with an error status and a body describing the overflow.
+	OverflowError Overflow = iota
+
+	// OverflowTruncate truncates the body to fit and sets the X-Payload-Truncated header field,
+	// compressed bodies can not be truncated and fall back to OverflowError.
+	OverflowTruncate
+
+	// OverflowStore puts the body in the ObjectStore and redirects the client to it,
+	// falling back to OverflowError if the store fails.
+	OverflowStore
+)
+
+// ObjectStore stores response bodies which are too large for the Lambda payload limit.
+type ObjectStore interface {
+	// Put stores the body and its header fields, returning the URL clients are redirected to.
+	Put(ctx context.Context, key string, header http.Header, body []byte) (string, error)
+}
+
+// PayloadLimit configures the enforcement of the response payload limit.
+type PayloadLimit struct {
+	// MaxBytes is the maximum size of the encoded response, defaults to DefaultMaxPayload.
+	MaxBytes int
+
+	// Overflow is the strategy applied to responses exceeding MaxBytes.
+	Overflow Overflow
+
+	// Status is the status code used by OverflowError, defaults to 502 Bad Gateway.
+	Status int
+
+	// Store is the object store used by OverflowStore.
+	Store ObjectStore
+}
+
+// limit enforces the payload limit on the encoded body, returning the body to send.
+func (w *ResponseWriter) limit(body string, binary bool) (string, bool) {
+	l := w.payloadLimit
+	if l == nil {
+		return body, binary
+	}
+
+	max := l.MaxBytes
+	if max == 0 {
+		max = DefaultMaxPayload
+	}
+
+	size := envelopeLen(w.header) + jsonStringLen(body)
+	if size <= max {
+		return body, binary
+	}
+
+	switch l.Overflow {
+	case OverflowTruncate:
+		if w.header.Get("Content-Encoding") == "" {
+			return w.truncate(max, binary)
+		}
+	case OverflowStore:
+		if l.Store != nil {
+			url, err := l.Store.Put(w.context(), newObjectKey(), w.header, w.buf.Bytes())
+			if err == nil {
+				return w.redirect(url)
+			}
+		}
+	}
+
+	return w.overflow(size, max)
+}
+
+// truncate truncates the buffered body so the encoded response fits in max bytes.
+func (w *ResponseWriter) truncate(max int, binary bool) (string, bool) {
+	w.header.Set("X-Payload-Truncated", "true")
+	w.header.Del("Content-Length")
+	w.writeHeaders()
+
+	avail := max - envelopeLen(w.header)
+
+	if binary {
+		n := (avail - 2) / 4 * 3
+		if n < 0 {
+			n = 0
+		} else if n > w.buf.Len() {
+			n = w.buf.Len()
+		}
+		return base64.StdEncoding.EncodeToString(w.buf.Bytes()[:n]), true
+	}
+
+	s := w.buf.String()
+	n, size := 0, 2
+	for n < len(s) {
+		r, width := utf8.DecodeRuneInString(s[n:])
+		size += jsonRuneLen(r, width)
+		if size > avail {
+			break
+		}
+		n += width
+	}
+
+	return s[:n], false
+}
+
+// redirect responds with a redirect to the stored body.
+func (w *ResponseWriter) redirect(url string) (string, bool) {
+	h := make(http.Header)
+	h.Set("Location", url)
+	h["Set-Cookie"] = w.header["Set-Cookie"]
+	w.header = h
+
+	w.out.StatusCode = http.StatusSeeOther
+	w.writeHeaders()
+
+	return "", false
+}
+
+// overflow responds with an error describing the overflow.
+func (w *ResponseWriter) overflow(size, max int) (string, bool) {
+	h := make(http.Header)
+	h.Set("Content-Type", "text/plain; charset=utf8")
+	w.header = h
+
+	w.out.StatusCode = w.payloadLimit.Status
+	if w.out.StatusCode == 0 {
+		w.out.StatusCode = http.StatusBadGateway
+	}
+	w.writeHeaders()
+
+	return fmt.Sprintf("Response of %d bytes exceeds the Lambda payload limit of %d bytes\n", size, max), false
+}
+
+// context returns the request context.
+func (w *ResponseWriter) context() context.Context {
+	if w.ctx == nil {
+		return context.Background()
+	}
+
+	return w.ctx
+}
+
+// newObjectKey returns a random object key.
+func newObjectKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// envelopeLen returns the estimated encoded size of the response excluding the body.
+func envelopeLen(h http.Header) int {
+	b, _ := json.Marshal(h)
+	return len(b) + envelopeSlack
+}
+
+// jsonStringLen returns the length of s encoded as a JSON string.
+func jsonStringLen(s string) int {
+	n := 2
+
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		n += jsonRuneLen(r, width)
+		i += width
+	}
+
+	return n
+}
+
+// jsonRuneLen returns the length of the rune encoded in a JSON string,
+// escaping the same characters as encoding/json and overestimating invalid utf-8.
+func jsonRuneLen(r rune, width int) int {
+	switch {
+	case r == '"' || r == '\\' || r == '\n' || r == '\r' || r == '\t':
+		return 2
+	case r < 0x20 || r == '<' || r == '>' || r == '&':
+		return 6
+	case r == utf8.RuneError && width == 1:
+		return 6
+	case r == '\u2028' || r == '\u2029':
+		return 6
+	default:
+		return width
+	}
+}
+
+// FileStore is an ObjectStore writing bodies to a local directory, intended for tests
+// and local development where the directory is served at URL.
+type FileStore struct {
+	// Dir is the directory bodies are written to.
+	Dir string
+
+	// URL is the base URL the directory is served from.
+	URL string
+}
+
+// Put implementation.
+func (s *FileStore) Put(ctx context.Context, key string, header http.Header, body []byte) (string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return "", errors.Wrap(err, "creating directory")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(s.Dir, key), body, 0644); err != nil {
+		return "", errors.Wrap(err, "writing body")
+	}
+
+	return strings.TrimRight(s.URL, "/") + "/" + key, nil
+}
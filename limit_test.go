@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/tj/assert"
+)
+
+func TestJSONStringLen(t *testing.T) {
+	cases := []string{
+		"",
+		"hello world",
+		`{"name": "Tobi"}`,
+		"line\nline\r\n\ttab",
+		"<script>alert('&')</script>",
+		"\x00\x01\x1f",
+		"héllo wörld ☃ 😀",
+		"separators    ",
+	}
+
+	for _, s := range cases {
+		b, err := json.Marshal(s)
+		assert.NoError(t, err)
+		assert.Equal(t, len(b), jsonStringLen(s), s)
+	}
+
+	// invalid utf-8 is replaced, the estimate is an upper bound
+	b, err := json.Marshal("invalid \xff\xfe utf8")
+	assert.NoError(t, err)
+	assert.True(t, jsonStringLen("invalid \xff\xfe utf8") >= len(b))
+}
+
+func newLimitedResponse(l PayloadLimit) *ResponseWriter {
+	w := NewResponse()
+	w.payloadLimit = &l
+	return w
+}
+
+func TestResponseWriter_limit_under(t *testing.T) {
+	w := newLimitedResponse(PayloadLimit{MaxBytes: 1024})
+	w.Write([]byte("hello"))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "hello", e.Body)
+}
+
+func TestResponseWriter_limit_error(t *testing.T) {
+	w := newLimitedResponse(PayloadLimit{MaxBytes: 1024, Status: 413})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Foo", "bar")
+	w.Write([]byte(strings.Repeat(`"<>"`, 300)))
+
+	e := w.End()
+	assert.Equal(t, 413, e.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf8", e.Headers["Content-Type"])
+	assert.Equal(t, "", e.Headers["X-Foo"])
+	assert.Equal(t, "Response of 4983 bytes exceeds the Lambda payload limit of 1024 bytes\n", e.Body)
+}
+
+func TestResponseWriter_limit_binary(t *testing.T) {
+	w := newLimitedResponse(PayloadLimit{MaxBytes: 1024})
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(make([]byte, 800))
+
+	e := w.End()
+	assert.Equal(t, 502, e.StatusCode)
+	assert.False(t, e.IsBase64Encoded)
+}
+
+func TestResponseWriter_limit_truncate(t *testing.T) {
+	w := newLimitedResponse(PayloadLimit{MaxBytes: 1024, Overflow: OverflowTruncate})
+	w.Write([]byte(strings.Repeat("héllo\n", 500)))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "true", e.Headers["X-Payload-Truncated"])
+	assert.True(t, strings.HasPrefix(strings.Repeat("héllo\n", 500), e.Body))
+
+	b, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.True(t, len(b) <= 1024, len(b))
+	assert.True(t, len(b) > 900, len(b))
+}
+
+func TestResponseWriter_limit_truncateBinary(t *testing.T) {
+	w := newLimitedResponse(PayloadLimit{MaxBytes: 1024, Overflow: OverflowTruncate})
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(make([]byte, 2000))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.True(t, e.IsBase64Encoded)
+
+	b, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.True(t, len(b) <= 1024, len(b))
+}
+
+func TestResponseWriter_limit_store(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gateway")
+	assert.NoError(t, err)
+
+	w := newLimitedResponse(PayloadLimit{
+		MaxBytes: 1024,
+		Overflow: OverflowStore,
+		Store:    &FileStore{Dir: dir, URL: "https://files.example.com/"},
+	})
+	w.Header().Add("Set-Cookie", "a=1")
+	body := strings.Repeat("hello world\n", 200)
+	w.Write([]byte(body))
+
+	e := w.End()
+	assert.Equal(t, 303, e.StatusCode)
+	assert.Equal(t, "", e.Body)
+	assert.Equal(t, "a=1", e.Headers["Set-Cookie"])
+
+	location := e.Headers["Location"]
+	assert.True(t, strings.HasPrefix(location, "https://files.example.com/"), location)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, strings.TrimPrefix(location, "https://files.example.com/")))
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(b))
+}
+
+type failingStore struct{}
+
+func (failingStore) Put(ctx context.Context, key string, header http.Header, body []byte) (string, error) {
+	return "", errors.New("boom")
+}
+
+func TestResponseWriter_limit_storeError(t *testing.T) {
+	w := newLimitedResponse(PayloadLimit{MaxBytes: 1024, Overflow: OverflowStore, Store: failingStore{}})
+	w.Write([]byte(strings.Repeat("hello world\n", 200)))
+
+	e := w.End()
+	assert.Equal(t, 502, e.StatusCode)
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestInLambda(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "")
+	t.Setenv("_LAMBDA_SERVER_PORT", "")
+	assert.False(t, inLambda())
+
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "127.0.0.1:9001")
+	assert.True(t, inLambda())
+}
+
+func TestLocalHandler(t *testing.T) {
+	gw := NewGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		ctx, ok := RequestContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "$default", ctx.Stage)
+		assert.Equal(t, r.Header.Get("X-Request-Id"), ctx.RequestID)
+		assert.NotEmpty(t, ctx.RequestID)
+		assert.Equal(t, "127.0.0.1", ctx.Identity.SourceIP)
+
+		assert.Equal(t, "127.0.0.1:0", r.RemoteAddr)
+		assert.Equal(t, []string{"ferret", "cat"}, r.URL.Query()["species"])
+		assert.Equal(t, []string{"apex1", "apex2"}, r.Header["X-Apex"])
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(b)
+	}))
+
+	s := httptest.NewServer(localHandler{invoke: gw.Invoke, mediaTypes: &gw.mediaTypes})
+	defer s.Close()
+
+	req, err := http.NewRequest("POST", s.URL+"/pets?species=ferret&species=cat", strings.NewReader("\x00\xff"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Add("X-Apex", "apex1")
+	req.Header.Add("X-Apex", "apex2")
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "\x00\xff", string(b))
+	assert.Equal(t, []string{"a=1", "b=2"}, res.Header["Set-Cookie"])
+}
+
+func TestLocalHandler_error(t *testing.T) {
+	s := httptest.NewServer(localHandler{invoke: func(context.Context, []byte) ([]byte, error) {
+		return nil, errors.New("boom")
+	}})
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.Equal(t, `{"message": "Internal server error"}`, string(b))
+}
@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"mime"
+	"strings"
+)
+
+// defaultMediaTypes are the media types sent as text unless registered otherwise.
+var defaultMediaTypes = map[string]bool{
+	"text/*":                            false,
+	"*/*+json":                          false,
+	"*/*+xml":                           false,
+	"application/json":                  false,
+	"application/xml":                   false,
+	"application/javascript":            false,
+	"application/x-www-form-urlencoded": false,
+	"application/graphql":               false,
+}
+
+// mediaTypes is a registry of media types sent as text or binary, typically
+// mirroring the binaryMediaTypes setting of a REST API.
+//
+// Patterns are exact media types, type wildcards such as "image/*", structured
+// syntax suffixes such as "+json" or "*/*+json", and "*/*". The most specific
+// registered pattern wins, registered patterns take precedence over the defaults,
+// and media types matching nothing are binary.
+type mediaTypes struct {
+	types map[string]bool
+}
+
+// add registers the patterns as binary or text.
+func (m *mediaTypes) add(binary bool, patterns ...string) {
+	if m.types == nil {
+		m.types = make(map[string]bool)
+	}
+
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if strings.HasPrefix(p, "+") {
+			p = "*/*" + p
+		}
+		m.types[p] = binary
+	}
+}
+
+// isBinary returns true if the content type represents binary data.
+func (m *mediaTypes) isBinary(kind string) bool {
+	mt, _, err := mime.ParseMediaType(kind)
+	if err != nil {
+		return true
+	}
+
+	if m != nil {
+		if binary, ok := lookupMediaType(m.types, mt); ok {
+			return binary
+		}
+	}
+
+	if binary, ok := lookupMediaType(defaultMediaTypes, mt); ok {
+		return binary
+	}
+
+	return true
+}
+
+// lookupMediaType returns the most specific pattern in types matching the media type.
+func lookupMediaType(types map[string]bool, mt string) (binary bool, ok bool) {
+	candidates := []string{mt}
+
+	slash := strings.Index(mt, "/")
+	if slash == -1 {
+		return false, false
+	}
+
+	if plus := strings.LastIndex(mt, "+"); plus > slash {
+		candidates = append(candidates, "*/*"+mt[plus:])
+	}
+
+	candidates = append(candidates, mt[:slash]+"/*", "*/*")
+
+	for _, c := range candidates {
+		if binary, ok := types[c]; ok {
+			return binary, true
+		}
+	}
+
+	return false, false
+}
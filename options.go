@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Option configures a Gateway.
+type Option func(*config)
+
+// config is the configuration shared by gateways.
+type config struct {
+	mediaTypes     mediaTypes
+	compression    *compression
+	limit          *PayloadLimit
+	stageConfig    reflect.Type
+	basePath       string
+	stageBasePath  bool
+	trustedProxies []*net.IPNet
+	timeout        *Timeout
+	traceContext   bool
+}
+
+// WithBinaryMediaTypes registers media type patterns whose responses are base64 encoded,
+// use it to mirror the API's binaryMediaTypes setting. Patterns may be exact media types,
+// wildcards such as "image/*" or "*/*", or structured syntax suffixes such as "+cbor".
+func WithBinaryMediaTypes(patterns ...string) Option {
+	return func(c *config) {
+		c.mediaTypes.add(true, patterns...)
+	}
+}
+
+// WithTextMediaTypes registers media type patterns whose responses are sent as text,
+// taking precedence over the defaults and less specific binary patterns.
+func WithTextMediaTypes(patterns ...string) Option {
+	return func(c *config) {
+		c.mediaTypes.add(false, patterns...)
+	}
+}
+
+// WithCompression enables compressing responses with a content coding
+// negotiated from the request's Accept-Encoding header field.
+func WithCompression(z Compression) Option {
+	return func(c *config) {
+		c.compression = newCompression(z)
+	}
+}
+
+// WithPayloadLimit configures how responses exceeding the Lambda payload limit are handled,
+// by default they are replaced with a 502 Bad Gateway response describing the overflow.
+func WithPayloadLimit(l PayloadLimit) Option {
+	return func(c *config) {
+		c.limit = &l
+	}
+}
+
+// payloadLimit returns the configured payload limit or the default.
+func (c *config) payloadLimit() *PayloadLimit {
+	if c.limit == nil {
+		return &PayloadLimit{}
+	}
+
+	return c.limit
+}
+
+// initWriter configures the response writer for the request with the
+// media types, compression and payload limit of the gateway. List header
+// fields may be split into several values, so all Accept-Encoding values are negotiated.
+func (c *config) initWriter(w *ResponseWriter, r *http.Request) {
+	w.mediaTypes = &c.mediaTypes
+	w.compression = c.compression
+	w.acceptEncoding = strings.Join(r.Header.Values("Accept-Encoding"), ",")
+	w.payloadLimit = c.payloadLimit()
+	w.ctx = r.Context()
+}
+
+// prepare returns the request with the per-request configuration applied.
+func (c *config) prepare(r *http.Request) (*http.Request, error) {
+	r = c.forwardedFor(r)
+	r = c.translateTrace(r)
+	r = c.stripBase(r)
+	return c.bindStage(r)
+}
@@ -2,12 +2,13 @@ package gateway
 
 import (
 	"context"
-	"encoding/base64"
+	"crypto/rand"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/pkg/errors"
@@ -32,28 +33,20 @@ func NewRequest(ctx context.Context, e events.APIGatewayProxyRequest) (*http.Req
 	}
 	u.RawQuery = q.Encode()
 
-	// base64 encoded body
-	body := e.Body
-	if e.IsBase64Encoded {
-		b, err := base64.StdEncoding.DecodeString(body)
-		if err != nil {
-			return nil, errors.Wrap(err, "decoding base64 body")
-		}
-		body = string(b)
-	}
-
 	// new request
-	req, err := http.NewRequest(e.HTTPMethod, u.String(), strings.NewReader(body))
+	req, err := http.NewRequest(e.HTTPMethod, u.String(), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating request")
 	}
 
+	// body, base64 encoded bodies are decoded while reading
+	if err := setBody(req, e.Body, e.IsBase64Encoded); err != nil {
+		return nil, err
+	}
+
 	// manually set RequestURI because NewRequest is for clients and req.RequestURI is for servers
 	req.RequestURI = u.RequestURI()
 
-	// remote addr
-	req.RemoteAddr = e.RequestContext.Identity.SourceIP
-
 	// header fields
 	for k, v := range e.Headers {
 		req.Header.Set(k, v)
@@ -64,8 +57,8 @@ func NewRequest(ctx context.Context, e events.APIGatewayProxyRequest) (*http.Req
 	}
 
 	// content-length
-	if req.Header.Get("Content-Length") == "" && body != "" {
-		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	if req.Header.Get("Content-Length") == "" && req.ContentLength > 0 {
+		req.Header.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
 	}
 
 	// custom fields
@@ -75,14 +68,96 @@ func NewRequest(ctx context.Context, e events.APIGatewayProxyRequest) (*http.Req
 	// custom context values
 	req = req.WithContext(newContext(ctx, e))
 
-	// xray support
-	if traceID := ctx.Value("x-amzn-trace-id"); traceID != nil {
-		req.Header.Set("X-Amzn-Trace-Id", fmt.Sprintf("%v", traceID))
+	// path parameters, including greedy {proxy+} parameters
+	for k, v := range e.PathParameters {
+		req.SetPathValue(k, v)
 	}
 
+	// xray support
+	req = setTraceID(req)
+
 	// host
 	req.URL.Host = req.Header.Get("Host")
 	req.Host = req.URL.Host
 
+	// scheme, tls, protocol and remote addr
+	setConnection(req, e.RequestContext.Protocol, e.RequestContext.Identity.SourceIP)
+
 	return req, nil
 }
+
+// EventFromRequest returns the event API Gateway sends for the request, consuming its body.
+//
+// Header fields and query string parameters are set as both single value fields,
+// holding their last values, and multi-value fields, and bodies are base64 encoded
+// when their media type represents binary. The Host and X-Forwarded-* header fields
+// are added, and the request context holds a new request id and the client address
+// of RemoteAddr. The event targets a greedy "/{proxy+}" resource of the $default stage.
+func EventFromRequest(r *http.Request) (events.APIGatewayProxyRequest, error) {
+	return eventFromRequest(r, nil)
+}
+
+// eventFromRequest returns the event for the request, with bodies of the binary media types of m base64 encoded.
+func eventFromRequest(r *http.Request, m *mediaTypes) (events.APIGatewayProxyRequest, error) {
+	var e events.APIGatewayProxyRequest
+
+	body, encoded, err := eventBody(r, m)
+	if err != nil {
+		return e, err
+	}
+
+	h, clientIP := forwardedHeader(r)
+	q := r.URL.Query()
+	now := time.Now()
+	domain := h.Get("Host")
+
+	e = events.APIGatewayProxyRequest{
+		Resource:        "/{proxy+}",
+		Path:            r.URL.Path,
+		HTTPMethod:      r.Method,
+		Headers:         make(map[string]string, len(h)),
+		PathParameters:  map[string]string{"proxy": strings.TrimPrefix(r.URL.Path, "/")},
+		Body:            body,
+		IsBase64Encoded: encoded,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID:        newRequestID(),
+			Stage:            "$default",
+			DomainName:       domain,
+			DomainPrefix:     strings.SplitN(domain, ".", 2)[0],
+			HTTPMethod:       r.Method,
+			Path:             r.URL.Path,
+			Protocol:         r.Proto,
+			ResourcePath:     "/{proxy+}",
+			RequestTime:      now.UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			RequestTimeEpoch: now.UnixNano() / int64(time.Millisecond),
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  clientIP,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	e.MultiValueHeaders = h
+	for k, values := range h {
+		e.Headers[k] = values[len(values)-1]
+	}
+
+	if len(q) > 0 {
+		e.QueryStringParameters = make(map[string]string, len(q))
+		e.MultiValueQueryStringParameters = q
+		for k, values := range q {
+			e.QueryStringParameters[k] = values[len(values)-1]
+		}
+	}
+
+	return e, nil
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -3,6 +3,8 @@ package gateway
 import (
 	"context"
 	"io/ioutil"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -89,7 +91,7 @@ func TestNewRequest_remoteAddr(t *testing.T) {
 	r, err := NewRequest(context.Background(), e)
 	assert.NoError(t, err)
 
-	assert.Equal(t, `1.2.3.4`, r.RemoteAddr)
+	assert.Equal(t, `1.2.3.4:0`, r.RemoteAddr)
 }
 
 func TestNewRequest_header(t *testing.T) {
@@ -185,6 +187,28 @@ func TestNewRequest_bodyBinary(t *testing.T) {
 	assert.Equal(t, "hello world\n", string(b))
 }
 
+func TestNewRequest_pathParameters(t *testing.T) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets/luna/photos/2020/beach.jpg",
+		Resource:   "/pets/{id}/photos/{proxy+}",
+		PathParameters: map[string]string{
+			"id":    "luna",
+			"proxy": "2020/beach.jpg",
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `luna`, r.PathValue("id"))
+	assert.Equal(t, `2020/beach.jpg`, r.PathValue("proxy"))
+
+	route, ok := RouteTemplate(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, `/pets/{id}/photos/{proxy+}`, route)
+}
+
 func TestNewRequest_context(t *testing.T) {
 	e := events.APIGatewayProxyRequest{}
 	ctx := context.WithValue(context.Background(), "key", "value")
@@ -193,3 +217,50 @@ func TestNewRequest_context(t *testing.T) {
 	v := r.Context().Value("key")
 	assert.Equal(t, "value", v)
 }
+
+func TestEventFromRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "https://example.com/pets?species=ferret&species=cat", strings.NewReader("\x89PNG"))
+	r.Header.Set("Content-Type", "image/png")
+	r.Header.Add("X-Apex", "apex1")
+	r.Header.Add("X-Apex", "apex2")
+
+	e, err := EventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "POST", e.HTTPMethod)
+	assert.Equal(t, "/pets", e.Path)
+	assert.Equal(t, "apex2", e.Headers["X-Apex"])
+	assert.Equal(t, []string{"apex1", "apex2"}, e.MultiValueHeaders["X-Apex"])
+	assert.Equal(t, "cat", e.QueryStringParameters["species"])
+	assert.Equal(t, []string{"ferret", "cat"}, e.MultiValueQueryStringParameters["species"])
+	assert.Equal(t, "iVBORw==", e.Body)
+	assert.True(t, e.IsBase64Encoded)
+	assert.Equal(t, "https", e.Headers["X-Forwarded-Proto"])
+	assert.Equal(t, "192.0.2.1", e.Headers["X-Forwarded-For"])
+	assert.Equal(t, "192.0.2.1", e.RequestContext.Identity.SourceIP)
+	assert.NotEmpty(t, e.RequestContext.RequestID)
+
+	req, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/pets?species=ferret&species=cat", req.URL.String())
+	assert.Equal(t, []string{"apex1", "apex2"}, req.Header["X-Apex"])
+	assert.Equal(t, "192.0.2.1:0", req.RemoteAddr)
+	assert.Equal(t, "\x89PNG", string(b))
+}
+
+func TestEventFromRequest_text(t *testing.T) {
+	r := httptest.NewRequest("POST", "/pets", strings.NewReader(`{"name":"Tobi"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	e, err := EventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `{"name":"Tobi"}`, e.Body)
+	assert.False(t, e.IsBase64Encoded)
+	assert.Equal(t, "http", e.Headers["X-Forwarded-Proto"])
+	assert.Nil(t, e.QueryStringParameters)
+}
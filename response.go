@@ -2,10 +2,11 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
-	"mime"
+	"fmt"
+	"io/ioutil"
 	"net/http"
-	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -18,6 +19,12 @@ type ResponseWriter struct {
 	header        http.Header
 	wroteHeader   bool
 	closeNotifyCh chan bool
+	mediaTypes    *mediaTypes
+
+	compression    *compression
+	acceptEncoding string
+	payloadLimit   *PayloadLimit
+	ctx            context.Context
 }
 
 // NewResponse returns a new response writer to capture http output.
@@ -56,7 +63,12 @@ func (w *ResponseWriter) WriteHeader(status int) {
 	}
 
 	w.out.StatusCode = status
+	w.writeHeaders()
+	w.wroteHeader = true
+}
 
+// writeHeaders sets the response header fields.
+func (w *ResponseWriter) writeHeaders() {
 	h := make(map[string]string)
 	mvh := make(map[string][]string)
 
@@ -70,7 +82,6 @@ func (w *ResponseWriter) WriteHeader(status int) {
 
 	w.out.Headers = h
 	w.out.MultiValueHeaders = mvh
-	w.wroteHeader = true
 }
 
 // CloseNotify notify when the response is closed
@@ -80,13 +91,7 @@ func (w *ResponseWriter) CloseNotify() <-chan bool {
 
 // End the request.
 func (w *ResponseWriter) End() events.APIGatewayProxyResponse {
-	w.out.IsBase64Encoded = isBinary(w.header)
-
-	if w.out.IsBase64Encoded {
-		w.out.Body = base64.StdEncoding.EncodeToString(w.buf.Bytes())
-	} else {
-		w.out.Body = w.buf.String()
-	}
+	w.out.Body, w.out.IsBase64Encoded = w.body()
 
 	// notify end
 	w.closeNotifyCh <- true
@@ -94,33 +99,69 @@ func (w *ResponseWriter) End() events.APIGatewayProxyResponse {
 	return w.out
 }
 
+// body returns the buffered body, compressed when enabled and
+// base64 encoded when it represents binary.
+func (w *ResponseWriter) body() (string, bool) {
+	if w.compression != nil && w.compression.compress(w.header, w.out.StatusCode, &w.buf, w.acceptEncoding) {
+		w.writeHeaders()
+	}
+
+	if isBinary(w.header, w.mediaTypes) {
+		return w.limit(base64.StdEncoding.EncodeToString(w.buf.Bytes()), true)
+	}
+
+	return w.limit(w.buf.String(), false)
+}
+
 // isBinary returns true if the response reprensents binary.
-func isBinary(h http.Header) bool {
+func isBinary(h http.Header, m *mediaTypes) bool {
 	switch {
-	case !isTextMime(h.Get("Content-Type")):
+	case m.isBinary(h.Get("Content-Type")):
 		return true
-	case h.Get("Content-Encoding") == "gzip":
+	case h.Get("Content-Encoding") != "" && h.Get("Content-Encoding") != "identity":
 		return true
 	default:
 		return false
 	}
 }
 
-// isTextMime returns true if the content type represents textual data.
+// isTextMime returns true if the content type represents textual data by default.
 func isTextMime(kind string) bool {
-	mt, _, err := mime.ParseMediaType(kind)
+	var m *mediaTypes
+	return !m.isBinary(kind)
+}
+
+// ResponseFromEvent returns the http.Response API Gateway sends for the response event,
+// decoding base64 encoded bodies. The Request field of the response is nil.
+func ResponseFromEvent(e events.APIGatewayProxyResponse) (*http.Response, error) {
+	return newHTTPResponse(e.StatusCode, e.Headers, e.MultiValueHeaders, e.Body, e.IsBase64Encoded)
+}
+
+// newHTTPResponse returns the http.Response for the fields of a response event,
+// multi-value header fields take precedence over single value fields.
+func newHTTPResponse(status int, h map[string]string, mvh map[string][]string, body string, encoded bool) (*http.Response, error) {
+	b, err := decodeBody(body, encoded)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	if strings.HasPrefix(mt, "text/") {
-		return true
+	header := make(http.Header)
+	for k, v := range h {
+		header.Set(k, v)
 	}
 
-	switch mt {
-	case "image/svg+xml", "application/json", "application/xml","application/javascript":
-		return true
-	default:
-		return false
+	for k, values := range mvh {
+		header[http.CanonicalHeaderKey(k)] = values
 	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+	}, nil
 }
@@ -2,8 +2,10 @@ package gateway
 
 import (
 	"bytes"
+	"io/ioutil"
 	"testing"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/tj/assert"
 )
 
@@ -105,3 +107,33 @@ func TestResponseWriter_WriteHeader(t *testing.T) {
 	assert.Equal(t, "Not Found\n", e.Body)
 	assert.Equal(t, "text/plain; charset=utf8", e.Headers["Content-Type"])
 }
+
+func TestResponseFromEvent(t *testing.T) {
+	res, err := ResponseFromEvent(events.APIGatewayProxyResponse{
+		StatusCode: 404,
+		Headers:    map[string]string{"content-type": "image/png", "X-Apex": "ignored"},
+		MultiValueHeaders: map[string][]string{
+			"X-Apex":     {"apex1", "apex2"},
+			"Set-Cookie": {"a=1", "b=2"},
+		},
+		Body:            "iVBORw==",
+		IsBase64Encoded: true,
+	})
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 404, res.StatusCode)
+	assert.Equal(t, "404 Not Found", res.Status)
+	assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"apex1", "apex2"}, res.Header["X-Apex"])
+	assert.Equal(t, []string{"a=1", "b=2"}, res.Header["Set-Cookie"])
+	assert.Equal(t, int64(4), res.ContentLength)
+	assert.Equal(t, "\x89PNG", string(b))
+}
+
+func TestResponseFromEvent_invalidBase64(t *testing.T) {
+	_, err := ResponseFromEvent(events.APIGatewayProxyResponse{StatusCode: 200, Body: "a===", IsBase64Encoded: true})
+	assert.EqualError(t, err, "decoding base64 body: illegal base64 data at input byte 1")
+}
@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// Server mirrors http.Server for use within AWS Lambda, its fields are
+// honoured by Invoke so code written for http.Server ports directly.
+type Server struct {
+	// Addr is the TCP address to listen on outside of Lambda, ":http" if empty.
+	Addr string
+
+	// Handler to invoke, http.DefaultServeMux if nil.
+	Handler http.Handler
+
+	// Options configure the underlying Gateway.
+	Options []Option
+
+	// ErrorLog specifies an optional logger for handler panics,
+	// the log package's standard logger is used if nil.
+	ErrorLog *log.Logger
+
+	// BaseContext optionally returns the base context of requests from the invocation context.
+	BaseContext func(context.Context) context.Context
+
+	// ReadTimeout is the maximum duration for reading the request body,
+	// reads fail with os.ErrDeadlineExceeded once it has elapsed.
+	ReadTimeout time.Duration
+
+	// WriteTimeout is the maximum duration of the handler, after which a 504 Gateway Timeout
+	// is sent, as with WithTimeout(Timeout{Max: WriteTimeout}).
+	WriteTimeout time.Duration
+
+	// MaxHeaderBytes is the maximum size of the request line and header fields, larger requests
+	// are responded to with 431 Request Header Fields Too Large. Defaults to http.DefaultMaxHeaderBytes.
+	MaxHeaderBytes int
+
+	once       sync.Once
+	gw         *Gateway
+	mu         sync.Mutex
+	onShutdown []func()
+	shutdown   bool
+	local      *http.Server
+}
+
+// ListenAndServe starts the Lambda runtime, running the shutdown hooks
+// when the runtime receives SIGTERM.
+//
+// Outside of Lambda it listens on Addr, passing each request through
+// the same event encoding and decoding as Invoke, and always returns
+// a non-nil error.
+func (s *Server) ListenAndServe() error {
+	if !inLambda() {
+		var c config
+		for _, o := range s.Options {
+			o(&c)
+		}
+
+		addr := s.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+
+		srv := &http.Server{
+			Addr:    addr,
+			Handler: localHandler{invoke: s.Invoke, mediaTypes: &c.mediaTypes},
+		}
+
+		s.mu.Lock()
+		s.local = srv
+		s.mu.Unlock()
+
+		return srv.ListenAndServe()
+	}
+
+	lambda.StartWithOptions(s, lambda.WithEnableSIGTERM(func() {
+		s.Shutdown(context.Background())
+	}))
+
+	return nil
+}
+
+// Invoke Handler implementation.
+func (s *Server) Invoke(ctx context.Context, payload []byte) (b []byte, err error) {
+	s.mu.Lock()
+	shutdown := s.shutdown
+	s.mu.Unlock()
+
+	if shutdown {
+		return []byte{}, http.ErrServerClosed
+	}
+
+	s.once.Do(func() {
+		var options []Option
+		if s.WriteTimeout > 0 {
+			options = append(options, WithTimeout(Timeout{Max: s.WriteTimeout}))
+		}
+		s.gw = NewGateway(http.HandlerFunc(s.serveHTTP), append(options, s.Options...)...)
+	})
+
+	if s.BaseContext != nil {
+		ctx = s.BaseContext(ctx)
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			if v != http.ErrAbortHandler {
+				s.logf("gateway: panic serving invocation: %v\n%s", v, debug.Stack())
+			}
+			b, err = []byte{}, fmt.Errorf("handler panic: %v", v)
+		}
+	}()
+
+	return s.gw.Invoke(ctx, payload)
+}
+
+// RegisterOnShutdown registers a function to call on Shutdown.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.mu.Lock()
+	s.onShutdown = append(s.onShutdown, f)
+	s.mu.Unlock()
+}
+
+// Shutdown stops accepting invocations and calls the registered shutdown hooks
+// concurrently, waiting for them to return or the context to be done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdown = true
+	hooks := s.onShutdown
+	local := s.local
+	s.mu.Unlock()
+
+	if local != nil {
+		local.Shutdown(ctx)
+	}
+
+	var wg sync.WaitGroup
+	for _, f := range hooks {
+		wg.Add(1)
+		go func(f func()) {
+			defer wg.Done()
+			f()
+		}(f)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// serveHTTP enforces the request limits before calling the handler.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	max := s.MaxHeaderBytes
+	if max <= 0 {
+		max = http.DefaultMaxHeaderBytes
+	}
+
+	if headerBytes(r) > max {
+		http.Error(w, http.StatusText(http.StatusRequestHeaderFieldsTooLarge), http.StatusRequestHeaderFieldsTooLarge)
+		return
+	}
+
+	if s.ReadTimeout > 0 && r.Body != nil && r.Body != http.NoBody {
+		r.Body = &deadlineBody{ReadCloser: r.Body, deadline: time.Now().Add(s.ReadTimeout)}
+	}
+
+	h := s.Handler
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+
+	h.ServeHTTP(w, r)
+}
+
+// logf logs to the ErrorLog or the standard logger.
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+		return
+	}
+
+	log.Printf(format, args...)
+}
+
+// headerBytes returns the size of the request line and header fields as sent over HTTP/1.1.
+func headerBytes(r *http.Request) int {
+	n := len(r.Method) + len(r.RequestURI) + len(r.Proto) + 4
+
+	for k, values := range r.Header {
+		for _, v := range values {
+			n += len(k) + len(v) + 4
+		}
+	}
+
+	return n
+}
+
+// deadlineBody is a request body failing reads after its deadline.
+type deadlineBody struct {
+	io.ReadCloser
+	deadline time.Time
+}
+
+// Read implementation.
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	if time.Now().After(b.deadline) {
+		return 0, os.ErrDeadlineExceeded
+	}
+
+	return b.ReadCloser.Read(p)
+}
@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestTimeout_deadline(t *testing.T) {
+	to := Timeout{Margin: time.Second, Max: time.Minute}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	lambda, _ := ctx.Deadline()
+	assert.Equal(t, lambda.Add(-time.Second), to.deadline(ctx))
+
+	to.Max = 5 * time.Second
+	d := to.deadline(ctx)
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), d, 100*time.Millisecond)
+}
+
+func TestConfig_serve_timeout(t *testing.T) {
+	var c config
+	WithTimeout(Timeout{Max: 100 * time.Millisecond})(&c)
+
+	release := make(chan struct{})
+	written := make(chan error, 1)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Partial", "true")
+		w.Write([]byte("partial"))
+		<-release
+		_, err := w.Write([]byte("late"))
+		written <- err
+	})
+
+	r, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	w := NewResponse()
+	c.serve(h, w, r)
+	close(release)
+
+	assert.Equal(t, http.ErrHandlerTimeout, <-written)
+
+	e := w.End()
+	assert.Equal(t, 504, e.StatusCode)
+	assert.Equal(t, "Gateway Timeout\n", e.Body)
+	assert.Equal(t, "", w.Header().Get("X-Partial"))
+}
+
+func TestConfig_serve_status(t *testing.T) {
+	var c config
+	WithTimeout(Timeout{Margin: time.Millisecond, Max: 50 * time.Millisecond, Status: 503})(&c)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+
+	r, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	w := NewResponse()
+	c.serve(h, w, r)
+
+	e := w.End()
+	assert.Equal(t, 503, e.StatusCode)
+	assert.Equal(t, "Service Unavailable\n", e.Body)
+}
+
+func TestTimeoutWriter_timeout_finished(t *testing.T) {
+	w := NewResponse()
+	tw := &timeoutWriter{w: w}
+
+	tw.WriteHeader(503)
+	tw.Write([]byte("custom"))
+	tw.finish()
+	tw.timeout(504)
+
+	e := w.End()
+	assert.Equal(t, 503, e.StatusCode)
+	assert.Equal(t, "custom", e.Body)
+}
+
+func TestConfig_serve_finished(t *testing.T) {
+	var c config
+	WithTimeout(Timeout{})(&c)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Context().Deadline()
+		assert.True(t, ok)
+		w.WriteHeader(201)
+		w.Write([]byte("created"))
+	})
+
+	r, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	w := NewResponse()
+	c.serve(h, w, r)
+
+	e := w.End()
+	assert.Equal(t, 201, e.StatusCode)
+	assert.Equal(t, "created", e.Body)
+}
+
+func TestConfig_serve_panic(t *testing.T) {
+	var c config
+	WithTimeout(Timeout{})(&c)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	r, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		c.serve(h, NewResponse(), r)
+	})
+}
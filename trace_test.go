@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+const (
+	xrayHeader  = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+	traceparent = "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01"
+)
+
+func TestTraceparentFromXRay(t *testing.T) {
+	tp, ok := TraceparentFromXRay(xrayHeader)
+	assert.True(t, ok)
+	assert.Equal(t, traceparent, tp)
+
+	tp, ok = TraceparentFromXRay("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0;Lineage=a87bd80c:1")
+	assert.True(t, ok)
+	assert.Equal(t, "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00", tp)
+
+	for _, h := range []string{
+		"",
+		"Root=1-5759e988-bd862e3fe1be46a994272793",
+		"Root=1-5759e988-bd862e3fe1be46a99427279;Parent=53995c3f42cd8ad8",
+		"Root=2-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8",
+		"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=0000000000000000",
+	} {
+		_, ok := TraceparentFromXRay(h)
+		assert.False(t, ok, h)
+	}
+}
+
+func TestXRayFromTraceparent(t *testing.T) {
+	h, ok := XRayFromTraceparent(traceparent)
+	assert.True(t, ok)
+	assert.Equal(t, xrayHeader, h)
+
+	h, ok = XRayFromTraceparent("00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00")
+	assert.True(t, ok)
+	assert.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0", h)
+
+	for _, tp := range []string{
+		"",
+		"ff-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01",
+		"00-00000000000000000000000000000000-53995c3f42cd8ad8-01",
+		"00-5759E988BD862E3FE1BE46A994272793-53995c3f42cd8ad8-01",
+		"00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8",
+	} {
+		_, ok := XRayFromTraceparent(tp)
+		assert.False(t, ok, tp)
+	}
+}
+
+func TestSetTraceID(t *testing.T) {
+	t.Run("invocation", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "x-amzn-trace-id", xrayHeader)
+		r, err := http.NewRequest("GET", "/", nil)
+		assert.NoError(t, err)
+		r.Header.Set("X-Amzn-Trace-Id", "Root=1-00000000-000000000000000000000001")
+
+		r = setTraceID(r.WithContext(ctx))
+		assert.Equal(t, xrayHeader, r.Header.Get("X-Amzn-Trace-Id"))
+
+		id, ok := TraceID(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, xrayHeader, id)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("_X_AMZN_TRACE_ID", xrayHeader)
+		r, err := http.NewRequest("GET", "/", nil)
+		assert.NoError(t, err)
+
+		r = setTraceID(r)
+		assert.Equal(t, xrayHeader, r.Header.Get("X-Amzn-Trace-Id"))
+	})
+
+	t.Run("header", func(t *testing.T) {
+		r, err := http.NewRequest("GET", "/", nil)
+		assert.NoError(t, err)
+		r.Header.Set("X-Amzn-Trace-Id", xrayHeader)
+
+		r = setTraceID(r)
+		id, ok := TraceID(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, xrayHeader, id)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		r, err := http.NewRequest("GET", "/", nil)
+		assert.NoError(t, err)
+
+		r = setTraceID(r)
+		_, ok := TraceID(r.Context())
+		assert.False(t, ok)
+	})
+}
+
+func TestConfig_translateTrace(t *testing.T) {
+	var c config
+	WithTraceContext()(&c)
+
+	r, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+	r.Header.Set("X-Amzn-Trace-Id", xrayHeader)
+	r.Header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	r = c.translateTrace(r)
+	assert.Equal(t, traceparent, r.Header.Get("Traceparent"))
+
+	r, err = http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+	r.Header.Set("Traceparent", traceparent)
+
+	r = c.translateTrace(r)
+	assert.Equal(t, xrayHeader, r.Header.Get("X-Amzn-Trace-Id"))
+
+	id, ok := TraceID(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, xrayHeader, id)
+}
@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
+)
+
+// Transport is an http.RoundTripper invoking a gateway in-process, so an ordinary
+// http.Client exercises the event encoding used in production. Each request is
+// encoded as an API Gateway event, and the response payload decoded.
+//
+// Failures of the payloads themselves, such as payloads exceeding the Lambda limit
+// or which are not valid JSON, are returned as errors rather than responses.
+type Transport struct {
+	// Gateway is invoked for each request, such as a Gateway or Server.
+	Gateway lambda.Handler
+
+	// MaxPayload is the maximum size of the request and response payloads, defaults to DefaultMaxPayload.
+	MaxPayload int
+}
+
+// RoundTrip implementation.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Body != nil {
+		defer r.Body.Close()
+	}
+
+	event, err := EventFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding event")
+	}
+
+	max := t.MaxPayload
+	if max <= 0 {
+		max = DefaultMaxPayload
+	}
+
+	if len(payload) > max {
+		return nil, errors.Errorf("request payload of %d bytes exceeds the limit of %d bytes", len(payload), max)
+	}
+
+	out, err := t.Gateway.Invoke(r.Context(), payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "invoking gateway")
+	}
+
+	if len(out) > max {
+		return nil, errors.Errorf("response payload of %d bytes exceeds the limit of %d bytes", len(out), max)
+	}
+
+	var e events.APIGatewayProxyResponse
+	if err := json.Unmarshal(out, &e); err != nil {
+		return nil, errors.Wrap(err, "decoding response payload")
+	}
+
+	res, err := ResponseFromEvent(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 100 || res.StatusCode > 999 {
+		return nil, errors.Errorf("malformed response payload: invalid status code %d", res.StatusCode)
+	}
+
+	res.Request = r
+	return res, nil
+}
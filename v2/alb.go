@@ -0,0 +1,274 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// NewALBGateway creates a gateway for Application Load Balancer target groups using the provided http.Handler.
+func NewALBGateway(h http.Handler, options ...Option) *ALBGateway {
+	gw := &ALBGateway{h: h}
+	for _, o := range options {
+		o(&gw.config)
+	}
+	return gw
+}
+
+// ALBGateway wrap a http handler to enable use as a lambda.Handler behind an Application Load Balancer.
+type ALBGateway struct {
+	h http.Handler
+	config
+}
+
+// Invoke Handler implementation
+func (gw *ALBGateway) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var evt events.ALBTargetGroupRequest
+
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return []byte{}, err
+	}
+
+	r, err := NewALBRequest(ctx, evt)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	r, err = gw.prepare(r)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	w := NewALBResponse(isMultiValueALB(evt))
+	gw.initWriter(&w.ResponseWriter, r)
+	w.payloadLimit = gw.payloadLimit(DefaultMaxALBPayload)
+	gw.serve(gw.h, &w.ResponseWriter, r)
+
+	resp := w.End()
+
+	return json.Marshal(&resp)
+}
+
+// NewALBRequest returns a new http.Request from the given ALB target group event.
+func NewALBRequest(ctx context.Context, e events.ALBTargetGroupRequest) (*http.Request, error) {
+	// path
+	u, err := url.Parse(e.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing path")
+	}
+
+	// querystring, the load balancer passes keys and values through url encoded
+	q := url.Values{}
+	if isMultiValueALB(e) {
+		for k, values := range e.MultiValueQueryStringParameters {
+			for _, v := range values {
+				q.Add(unescapeALB(k), unescapeALB(v))
+			}
+		}
+	} else {
+		for k, v := range e.QueryStringParameters {
+			q.Set(unescapeALB(k), unescapeALB(v))
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	// new request
+	req, err := http.NewRequest(e.HTTPMethod, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+
+	// body, base64 encoded bodies are decoded while reading
+	if err := setBody(req, e.Body, e.IsBase64Encoded); err != nil {
+		return nil, err
+	}
+
+	// manually set RequestURI because NewRequest is for clients and req.RequestURI is for servers
+	req.RequestURI = u.RequestURI()
+
+	// header fields
+	if isMultiValueALB(e) {
+		for k, values := range e.MultiValueHeaders {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	} else {
+		for k, v := range e.Headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	// content-length
+	if req.Header.Get("Content-Length") == "" && req.ContentLength > 0 {
+		req.Header.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
+	}
+
+	// custom context values
+	req = req.WithContext(newALBContext(ctx, e))
+
+	// xray support
+	req = setTraceID(req)
+
+	// host
+	req.URL.Host = req.Header.Get("Host")
+	req.Host = req.URL.Host
+
+	// scheme and remote addr, the load balancer appends the client address to X-Forwarded-For
+	var remoteIP string
+	if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+		parts := strings.Split(xff, ",")
+		remoteIP = strings.TrimSpace(parts[len(parts)-1])
+	}
+
+	setConnection(req, "", remoteIP)
+
+	return req, nil
+}
+
+// ALBEventFromRequest returns the event an Application Load Balancer sends for the
+// request, consuming its body. multiValue must match whether the target group has
+// multi-value headers enabled.
+//
+// As with the load balancer, header field names are lower-cased, query string
+// parameters are url encoded, and bodies are base64 encoded when their media type
+// represents binary. Without multi-value headers only the last value of repeated
+// header fields and query string parameters is kept. The X-Forwarded-* header
+// fields are added with the client address of RemoteAddr.
+func ALBEventFromRequest(r *http.Request, multiValue bool) (events.ALBTargetGroupRequest, error) {
+	var e events.ALBTargetGroupRequest
+
+	body, encoded, err := eventBody(r, nil)
+	if err != nil {
+		return e, err
+	}
+
+	h := make(map[string][]string)
+	fields, _ := forwardedHeader(r)
+	for k, values := range fields {
+		h[strings.ToLower(k)] = values
+	}
+
+	q := make(map[string][]string)
+	for k, values := range r.URL.Query() {
+		k = url.QueryEscape(k)
+		for _, v := range values {
+			q[k] = append(q[k], url.QueryEscape(v))
+		}
+	}
+
+	e = events.ALBTargetGroupRequest{
+		HTTPMethod:      r.Method,
+		Path:            r.URL.EscapedPath(),
+		Body:            body,
+		IsBase64Encoded: encoded,
+		RequestContext: events.ALBTargetGroupRequestContext{
+			ELB: events.ELBContext{},
+		},
+	}
+
+	if multiValue {
+		e.MultiValueHeaders = h
+		e.MultiValueQueryStringParameters = q
+		return e, nil
+	}
+
+	e.Headers = make(map[string]string, len(h))
+	for k, values := range h {
+		e.Headers[k] = values[len(values)-1]
+	}
+
+	e.QueryStringParameters = make(map[string]string, len(q))
+	for k, values := range q {
+		e.QueryStringParameters[k] = values[len(values)-1]
+	}
+
+	return e, nil
+}
+
+// ALBResponseFromEvent returns the http.Response an Application Load Balancer sends for the
+// response event, decoding base64 encoded bodies. The Request field of the response is nil.
+func ALBResponseFromEvent(e events.ALBTargetGroupResponse) (*http.Response, error) {
+	res, err := newHTTPResponse(e.StatusCode, e.Headers, e.MultiValueHeaders, e.Body, e.IsBase64Encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.StatusDescription != "" {
+		res.Status = e.StatusDescription
+	}
+
+	return res, nil
+}
+
+// isMultiValueALB returns true if the target group has multi-value headers enabled,
+// in which case the load balancer only populates the multi-value fields.
+func isMultiValueALB(e events.ALBTargetGroupRequest) bool {
+	return e.MultiValueHeaders != nil || e.MultiValueQueryStringParameters != nil
+}
+
+// unescapeALB decodes a query string key or value, the load balancer passes
+// through invalid escapes untouched so those are returned as-is.
+func unescapeALB(s string) string {
+	v, err := url.QueryUnescape(s)
+	if err != nil {
+		return s
+	}
+
+	return v
+}
+
+// ALBResponseWriter implements the http.ResponseWriter interface
+// in order to support the Application Load Balancer Lambda "protocol".
+type ALBResponseWriter struct {
+	ResponseWriter
+	multiValue bool
+}
+
+// NewALBResponse returns a new response writer to capture http output for a load balancer,
+// multiValue must match whether the target group has multi-value headers enabled.
+func NewALBResponse(multiValue bool) *ALBResponseWriter {
+	return &ALBResponseWriter{
+		ResponseWriter: ResponseWriter{
+			closeNotifyCh: make(chan bool, 1),
+		},
+		multiValue: multiValue,
+	}
+}
+
+// End the request.
+func (w *ALBResponseWriter) End() events.ALBTargetGroupResponse {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	var out events.ALBTargetGroupResponse
+	out.Body, out.IsBase64Encoded = w.body()
+	out.StatusCode = w.out.StatusCode
+	out.StatusDescription = fmt.Sprintf("%d %s", w.out.StatusCode, http.StatusText(w.out.StatusCode))
+
+	// the load balancer only reads the header field matching the target group mode
+	if w.multiValue {
+		out.MultiValueHeaders = make(map[string][]string)
+		for k, v := range w.header {
+			out.MultiValueHeaders[k] = v
+		}
+	} else {
+		out.Headers = foldHeaders(w.header)
+		if v := w.header["Set-Cookie"]; len(v) > 0 {
+			out.Headers["Set-Cookie"] = v[len(v)-1]
+		}
+	}
+
+	// notify end
+	w.closeNotifyCh <- true
+
+	return out
+}
@@ -0,0 +1,223 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestNewALBRequest_queryString(t *testing.T) {
+	e := events.ALBTargetGroupRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets",
+		QueryStringParameters: map[string]string{
+			"name":   "Tobi%20Ferret",
+			"broken": "100%",
+		},
+	}
+
+	r, err := NewALBRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `Tobi Ferret`, r.URL.Query().Get("name"))
+	assert.Equal(t, `100%`, r.URL.Query().Get("broken"))
+}
+
+func TestNewALBRequest_multiValue(t *testing.T) {
+	e := events.ALBTargetGroupRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets",
+		MultiValueQueryStringParameters: map[string][]string{
+			"species%5B%5D": {"ferret", "cat"},
+		},
+		MultiValueHeaders: map[string][]string{
+			"host":            {"example.com"},
+			"x-apex":          {"apex1", "apex2"},
+			"x-forwarded-for": {"10.0.0.1, 1.2.3.4"},
+		},
+		Headers: map[string]string{
+			"x-ignored": "ignored",
+		},
+		RequestContext: events.ALBTargetGroupRequestContext{
+			ELB: events.ELBContext{TargetGroupArn: "arn:tg"},
+		},
+	}
+
+	r, err := NewALBRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ferret", "cat"}, r.URL.Query()["species[]"])
+	assert.Equal(t, `example.com`, r.Host)
+	assert.Equal(t, []string{"apex1", "apex2"}, r.Header["X-Apex"])
+	assert.Equal(t, ``, r.Header.Get("X-Ignored"))
+	assert.Equal(t, `1.2.3.4:0`, r.RemoteAddr)
+
+	c, ok := ALBRequestContext(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, `arn:tg`, c.ELB.TargetGroupArn)
+}
+
+func TestNewALBRequest_bodyBinary(t *testing.T) {
+	e := events.ALBTargetGroupRequest{
+		HTTPMethod:      "POST",
+		Path:            "/pets",
+		Body:            `aGVsbG8gd29ybGQK`,
+		IsBase64Encoded: true,
+	}
+
+	r, err := NewALBRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(r.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "hello world\n", string(b))
+}
+
+func TestALBResponseWriter_End(t *testing.T) {
+	w := NewALBResponse(false)
+	w.Header().Add("X-APEX", "apex1")
+	w.Header().Add("X-APEX", "apex2")
+	w.WriteHeader(404)
+	w.Write([]byte("Not Found\n"))
+
+	e := w.End()
+	assert.Equal(t, 404, e.StatusCode)
+	assert.Equal(t, "404 Not Found", e.StatusDescription)
+	assert.Equal(t, "Not Found\n", e.Body)
+	assert.Equal(t, "apex1, apex2", e.Headers["X-Apex"])
+	assert.Nil(t, e.MultiValueHeaders)
+	assert.False(t, e.IsBase64Encoded)
+}
+
+func TestALBResponseWriter_End_multiValue(t *testing.T) {
+	w := NewALBResponse(true)
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Write([]byte("data"))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "200 OK", e.StatusDescription)
+	assert.Equal(t, "ZGF0YQ==", e.Body)
+	assert.True(t, e.IsBase64Encoded)
+	assert.Equal(t, []string{"a=1", "b=2"}, e.MultiValueHeaders["Set-Cookie"])
+	assert.Nil(t, e.Headers)
+}
+
+func TestALBGateway_Invoke(t *testing.T) {
+	e := []byte(`{"httpMethod": "GET", "path": "/pets", "multiValueHeaders": {"host": ["example.com"]}, "requestContext": {"elb": {"targetGroupArn": "arn:tg"}}}`)
+
+	gw := NewALBGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+
+	var resp events.ALBTargetGroupResponse
+	assert.NoError(t, json.Unmarshal(payload, &resp))
+	assert.Equal(t, "200 OK", resp.StatusDescription)
+	assert.Equal(t, "example.com", resp.Body)
+	assert.Equal(t, []string{"text/plain; charset=utf8"}, resp.MultiValueHeaders["Content-Type"])
+}
+
+func TestALBGateway_Invoke_payloadLimit(t *testing.T) {
+	e := []byte(`{"httpMethod": "GET", "path": "/pets", "headers": {}, "requestContext": {"elb": {"targetGroupArn": "arn:tg"}}}`)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 2*1024*1024)))
+	})
+
+	cases := []struct {
+		name    string
+		gateway interface {
+			Invoke(context.Context, []byte) ([]byte, error)
+		}
+	}{
+		{"alb", NewALBGateway(h)},
+		{"detected", NewGateway(h)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			payload, err := c.gateway.Invoke(context.Background(), e)
+			assert.NoError(t, err)
+
+			var resp events.ALBTargetGroupResponse
+			assert.NoError(t, json.Unmarshal(payload, &resp))
+			assert.Equal(t, 502, resp.StatusCode)
+			assert.Contains(t, resp.Body, "exceeds the Lambda payload limit of 1048576 bytes")
+		})
+	}
+
+	gw := NewALBGateway(h, WithPayloadLimit(PayloadLimit{MaxBytes: DefaultMaxPayload}))
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+
+	var resp events.ALBTargetGroupResponse
+	assert.NoError(t, json.Unmarshal(payload, &resp))
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestALBEventFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/pets?name=Tobi%20Ferret&species=ferret&species=cat", nil)
+	r.Header.Add("X-Apex", "apex1")
+	r.Header.Add("X-Apex", "apex2")
+
+	e, err := ALBEventFromRequest(r, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Tobi+Ferret", e.QueryStringParameters["name"])
+	assert.Equal(t, "cat", e.QueryStringParameters["species"])
+	assert.Equal(t, "apex2", e.Headers["x-apex"])
+	assert.Nil(t, e.MultiValueHeaders)
+
+	e, err = ALBEventFromRequest(r, true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ferret", "cat"}, e.MultiValueQueryStringParameters["species"])
+	assert.Equal(t, []string{"apex1", "apex2"}, e.MultiValueHeaders["x-apex"])
+	assert.Equal(t, []string{"192.0.2.1"}, e.MultiValueHeaders["x-forwarded-for"])
+	assert.Nil(t, e.Headers)
+
+	req, err := NewALBRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Tobi Ferret", req.URL.Query().Get("name"))
+	assert.Equal(t, []string{"apex1", "apex2"}, req.Header["X-Apex"])
+	assert.Equal(t, "192.0.2.1:0", req.RemoteAddr)
+
+	payload, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	kind, err := detectEvent(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, eventALB, kind)
+}
+
+func TestALBResponseFromEvent(t *testing.T) {
+	res, err := ALBResponseFromEvent(events.ALBTargetGroupResponse{
+		StatusCode:        404,
+		StatusDescription: "404 Not Found",
+		Headers:           map[string]string{"X-Apex": "apex1, apex2"},
+		Body:              "Not Found\n",
+	})
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 404, res.StatusCode)
+	assert.Equal(t, "404 Not Found", res.Status)
+	assert.Equal(t, "apex1, apex2", res.Header.Get("X-Apex"))
+	assert.Equal(t, "Not Found\n", string(b))
+}
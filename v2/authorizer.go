@@ -0,0 +1,178 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// ErrNoAuthorizer is returned by DecodeAuthorizer when the request has no authorizer context.
+var ErrNoAuthorizer = errors.New("no authorizer context")
+
+// authorizer returns the HTTP API authorizer description stored in ctx.
+func authorizer(ctx context.Context) (*events.APIGatewayV2HTTPRequestContextAuthorizerDescription, bool) {
+	c, ok := RequestContext(ctx)
+	if !ok || c.Authorizer == nil {
+		return nil, false
+	}
+
+	return c.Authorizer, true
+}
+
+// JWTClaims returns the claims of a JWT authorizer stored in ctx, including the
+// "scope" claim when API Gateway only provides the scopes. For REST APIs and
+// 1.0 payloads these are the claims of an Amazon Cognito user pool or JWT authorizer.
+func JWTClaims(ctx context.Context) (Claims, bool) {
+	if c, ok := ProxyRequestContext(ctx); ok {
+		return proxyClaims(c)
+	}
+
+	a, ok := authorizer(ctx)
+	if !ok || a.JWT == nil {
+		return nil, false
+	}
+
+	claims := make(Claims, len(a.JWT.Claims)+1)
+	for k, v := range a.JWT.Claims {
+		claims[k] = v
+	}
+
+	if _, ok := claims["scope"]; !ok && len(a.JWT.Scopes) > 0 {
+		claims["scope"] = strings.Join(a.JWT.Scopes, " ")
+	}
+
+	return claims, true
+}
+
+// proxyClaims returns the claims of the 1.0 payload request context.
+func proxyClaims(c events.APIGatewayProxyRequestContext) (Claims, bool) {
+	m, ok := c.Authorizer["claims"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	claims := make(Claims, len(m))
+	for k, v := range m {
+		claims[k] = claimString(v)
+	}
+
+	return claims, true
+}
+
+// LambdaAuthorizerContext returns the context of a Lambda authorizer stored in ctx.
+// For REST APIs and 1.0 payloads this includes its "principalId".
+func LambdaAuthorizerContext(ctx context.Context) (map[string]interface{}, bool) {
+	if c, ok := ProxyRequestContext(ctx); ok {
+		if len(c.Authorizer) == 0 {
+			return nil, false
+		}
+
+		if _, ok := c.Authorizer["claims"]; ok {
+			return nil, false
+		}
+
+		return c.Authorizer, true
+	}
+
+	a, ok := authorizer(ctx)
+	if !ok || a.Lambda == nil {
+		return nil, false
+	}
+
+	return a.Lambda, true
+}
+
+// IAMIdentity returns the caller of a request using IAM authorization,
+// ok is false when the request was not signed. For REST APIs and 1.0 payloads
+// the description is populated from the request context identity.
+func IAMIdentity(ctx context.Context) (events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription, bool) {
+	if c, ok := ProxyRequestContext(ctx); ok {
+		if c.Identity.AccessKey == "" {
+			return events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{}, false
+		}
+
+		return proxyIAMIdentity(c.Identity), true
+	}
+
+	a, ok := authorizer(ctx)
+	if !ok || a.IAM == nil {
+		return events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{}, false
+	}
+
+	return *a.IAM, true
+}
+
+// proxyIAMIdentity returns the IAM description of the 1.0 payload identity.
+func proxyIAMIdentity(id events.APIGatewayRequestIdentity) events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription {
+	iam := events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{
+		AccessKey: id.AccessKey,
+		AccountID: id.AccountID,
+		CallerID:  id.Caller,
+		UserARN:   id.UserArn,
+		UserID:    id.User,
+		CognitoIdentity: events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity{
+			IdentityID:     id.CognitoIdentityID,
+			IdentityPoolID: id.CognitoIdentityPoolID,
+		},
+	}
+
+	if id.CognitoAuthenticationType != "" {
+		iam.CognitoIdentity.AMR = append(iam.CognitoIdentity.AMR, id.CognitoAuthenticationType)
+	}
+
+	if id.CognitoAuthenticationProvider != "" {
+		iam.CognitoIdentity.AMR = append(iam.CognitoIdentity.AMR, strings.Split(id.CognitoAuthenticationProvider, ",")...)
+	}
+
+	return iam
+}
+
+// CognitoIdentity returns the caller of a request signed with Amazon Cognito identity
+// pool credentials, ok is false for other requests.
+func CognitoIdentity(ctx context.Context) (events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity, bool) {
+	if c, ok := ProxyRequestContext(ctx); ok {
+		if c.Identity.CognitoIdentityID == "" {
+			return events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity{}, false
+		}
+
+		return proxyIAMIdentity(c.Identity).CognitoIdentity, true
+	}
+
+	iam, ok := IAMIdentity(ctx)
+	if !ok || iam.CognitoIdentity.IdentityID == "" {
+		return events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity{}, false
+	}
+
+	return iam.CognitoIdentity, true
+}
+
+// DecodeAuthorizer decodes the claims of a JWT or Cognito user pool authorizer
+// or the context of a Lambda authorizer stored in ctx into v, using encoding/json.
+func DecodeAuthorizer(ctx context.Context, v interface{}) error {
+	if claims, ok := JWTClaims(ctx); ok {
+		return decodeAuthorizer(claims, v)
+	}
+
+	if m, ok := LambdaAuthorizerContext(ctx); ok {
+		return decodeAuthorizer(m, v)
+	}
+
+	return ErrNoAuthorizer
+}
+
+// claimString returns the claim value as a string.
+func claimString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
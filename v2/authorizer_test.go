@@ -0,0 +1,224 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestJWTClaims(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayV2HTTPRequest{
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+				JWT: &events.APIGatewayV2HTTPRequestContextAuthorizerJWTDescription{
+					Claims: map[string]string{
+						"sub":            "1234",
+						"exp":            "1700000000",
+						"cognito:groups": "[admin users]",
+					},
+					Scopes: []string{"pets:read", "pets:write"},
+				},
+			},
+		},
+	})
+
+	c, ok := JWTClaims(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "1234", c.String("sub"))
+	assert.Equal(t, []string{"admin", "users"}, c.Groups())
+	assert.Equal(t, []string{"pets:read", "pets:write"}, c.Scopes())
+
+	var v struct {
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp,string"`
+	}
+	assert.NoError(t, DecodeAuthorizer(ctx, &v))
+	assert.Equal(t, "1234", v.Sub)
+	assert.Equal(t, int64(1700000000), v.Exp)
+}
+
+func TestLambdaAuthorizerContext(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayV2HTTPRequest{
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+				Lambda: map[string]interface{}{
+					"tenant": "acme",
+					"admin":  true,
+				},
+			},
+		},
+	})
+
+	m, ok := LambdaAuthorizerContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", m["tenant"])
+
+	_, ok = JWTClaims(ctx)
+	assert.False(t, ok)
+
+	var v struct {
+		Tenant string `json:"tenant"`
+		Admin  bool   `json:"admin"`
+	}
+	assert.NoError(t, DecodeAuthorizer(ctx, &v))
+	assert.Equal(t, "acme", v.Tenant)
+	assert.True(t, v.Admin)
+}
+
+func TestIAMIdentity(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayV2HTTPRequest{
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+				IAM: &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{
+					AccessKey: "AKIA",
+					UserARN:   "arn:aws:iam::123456789012:user/tobi",
+					CognitoIdentity: events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity{
+						AMR:            []string{"authenticated"},
+						IdentityID:     "us-east-1:abc",
+						IdentityPoolID: "us-east-1:pool",
+					},
+				},
+			},
+		},
+	})
+
+	id, ok := IAMIdentity(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/tobi", id.UserARN)
+
+	cognito, ok := CognitoIdentity(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "us-east-1:pool", cognito.IdentityPoolID)
+}
+
+func TestAuthorizer_none(t *testing.T) {
+	ctx := newContext(context.Background(), events.APIGatewayV2HTTPRequest{})
+
+	_, ok := JWTClaims(ctx)
+	assert.False(t, ok)
+
+	_, ok = LambdaAuthorizerContext(ctx)
+	assert.False(t, ok)
+
+	_, ok = IAMIdentity(ctx)
+	assert.False(t, ok)
+
+	_, ok = CognitoIdentity(ctx)
+	assert.False(t, ok)
+
+	assert.Equal(t, ErrNoAuthorizer, DecodeAuthorizer(ctx, &struct{}{}))
+}
+
+func TestJWTClaims_proxy(t *testing.T) {
+	r, err := NewProxyRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets",
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"claims": map[string]interface{}{
+					"sub":            "1234",
+					"exp":            1700000000.0,
+					"cognito:groups": "admin,users",
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	ctx := r.Context()
+
+	c, ok := JWTClaims(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "1234", c.String("sub"))
+	assert.Equal(t, "1700000000", c.String("exp"))
+	assert.Equal(t, []string{"admin", "users"}, c.Groups())
+
+	_, ok = LambdaAuthorizerContext(ctx)
+	assert.False(t, ok)
+
+	var v struct {
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp,string"`
+	}
+	assert.NoError(t, DecodeAuthorizer(ctx, &v))
+	assert.Equal(t, "1234", v.Sub)
+	assert.Equal(t, int64(1700000000), v.Exp)
+}
+
+func TestLambdaAuthorizerContext_proxy(t *testing.T) {
+	ctx := newProxyContext(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{
+				"principalId": "user-1",
+				"tenant":      "acme",
+				"admin":       true,
+			},
+		},
+	})
+
+	m, ok := LambdaAuthorizerContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", m["tenant"])
+
+	_, ok = JWTClaims(ctx)
+	assert.False(t, ok)
+
+	var v struct {
+		PrincipalID string `json:"principalId"`
+		Admin       bool   `json:"admin"`
+	}
+	assert.NoError(t, DecodeAuthorizer(ctx, &v))
+	assert.Equal(t, "user-1", v.PrincipalID)
+	assert.True(t, v.Admin)
+}
+
+func TestIAMIdentity_proxy(t *testing.T) {
+	ctx := newProxyContext(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Identity: events.APIGatewayRequestIdentity{
+				AccessKey:                     "AKIA",
+				AccountID:                     "123456789012",
+				UserArn:                       "arn:aws:iam::123456789012:user/tobi",
+				CognitoIdentityID:             "us-east-1:abc",
+				CognitoIdentityPoolID:         "us-east-1:pool",
+				CognitoAuthenticationType:     "authenticated",
+				CognitoAuthenticationProvider: "cognito-idp.us-east-1.amazonaws.com/us-east-1_xxx,cognito-idp.us-east-1.amazonaws.com/us-east-1_xxx:CognitoSignIn:1234",
+			},
+		},
+	})
+
+	id, ok := IAMIdentity(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "AKIA", id.AccessKey)
+	assert.Equal(t, "123456789012", id.AccountID)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/tobi", id.UserARN)
+
+	cognito, ok := CognitoIdentity(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "us-east-1:abc", cognito.IdentityID)
+	assert.Equal(t, "us-east-1:pool", cognito.IdentityPoolID)
+	assert.Equal(t, []string{
+		"authenticated",
+		"cognito-idp.us-east-1.amazonaws.com/us-east-1_xxx",
+		"cognito-idp.us-east-1.amazonaws.com/us-east-1_xxx:CognitoSignIn:1234",
+	}, cognito.AMR)
+}
+
+func TestAuthorizer_proxyNone(t *testing.T) {
+	ctx := newProxyContext(context.Background(), events.APIGatewayProxyRequest{})
+
+	_, ok := JWTClaims(ctx)
+	assert.False(t, ok)
+
+	_, ok = LambdaAuthorizerContext(ctx)
+	assert.False(t, ok)
+
+	_, ok = IAMIdentity(ctx)
+	assert.False(t, ok)
+
+	_, ok = CognitoIdentity(ctx)
+	assert.False(t, ok)
+
+	assert.Equal(t, ErrNoAuthorizer, DecodeAuthorizer(ctx, &struct{}{}))
+}
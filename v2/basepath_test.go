@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestConfig_stripBase(t *testing.T) {
+	cases := []struct {
+		name    string
+		options []Option
+		event   events.APIGatewayV2HTTPRequest
+		path    string
+		uri     string
+		base    string
+	}{
+		{
+			name:    "custom domain",
+			options: []Option{WithBasePath("billing/")},
+			event: events.APIGatewayV2HTTPRequest{
+				RawPath:        "/billing/invoices",
+				RawQueryString: "page=2",
+			},
+			path: "/invoices",
+			uri:  "/invoices?page=2",
+			base: "/billing",
+		},
+		{
+			name:    "escaped path",
+			options: []Option{WithBasePath("/billing")},
+			event: events.APIGatewayV2HTTPRequest{
+				RawPath: "/billing/files/a%2Fb",
+			},
+			path: "/files/a/b",
+			uri:  "/files/a%2Fb",
+			base: "/billing",
+		},
+		{
+			name:    "outside base path",
+			options: []Option{WithBasePath("/billing")},
+			event:   events.APIGatewayV2HTTPRequest{RawPath: "/billings"},
+			path:    "/billings",
+			uri:     "/billings",
+		},
+		{
+			name:    "stage prefix",
+			options: []Option{WithStageBasePath()},
+			event: events.APIGatewayV2HTTPRequest{
+				RawPath: "/prod/pets",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "prod",
+				},
+			},
+			path: "/pets",
+			uri:  "/pets",
+			base: "/prod",
+		},
+		{
+			name:    "default stage",
+			options: []Option{WithStageBasePath()},
+			event: events.APIGatewayV2HTTPRequest{
+				RawPath: "/$default/pets",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+				},
+			},
+			path: "/$default/pets",
+			uri:  "/$default/pets",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var conf config
+			for _, o := range c.options {
+				o(&conf)
+			}
+
+			c.event.RequestContext.HTTP.Method = "GET"
+			r, err := NewRequest(context.Background(), c.event)
+			assert.NoError(t, err)
+
+			r = conf.stripBase(r)
+			assert.Equal(t, c.path, r.URL.Path)
+			assert.Equal(t, c.uri, r.RequestURI)
+			assert.Equal(t, c.base, BasePath(r.Context()))
+		})
+	}
+}
+
+func TestConfig_stripBase_proxy(t *testing.T) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets",
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Stage: "prod",
+			Path:  "/prod/pets",
+		},
+	}
+
+	r, err := NewProxyRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	var c config
+	WithStageBasePath()(&c)
+	r = c.stripBase(r)
+
+	assert.Equal(t, "/pets", r.URL.Path)
+	assert.Equal(t, "/prod", BasePath(r.Context()))
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	e := events.APIGatewayV2HTTPRequest{
+		RawPath: "/billing/invoices",
+		Headers: map[string]string{
+			"Host": "internal.example.com",
+		},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			DomainName: "api.example.com",
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: "GET",
+			},
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	var c config
+	WithBasePath("/billing")(&c)
+	r = c.stripBase(r)
+
+	assert.Equal(t, "https://api.example.com/billing/login", AbsoluteURL(r, "/login"))
+
+	r, err = http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+	r.Host = "localhost:3000"
+	assert.Equal(t, "https://localhost:3000/login", AbsoluteURL(r, "/login"))
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestClaims(t *testing.T) {
+	c := Claims{
+		"sub":            "1234",
+		"exp":            "1700000000",
+		"email":          "tobi@example.com",
+		"scope":          "pets:read pets:write",
+		"cognito:groups": "[admin users]",
+		"roles":          `["owner","viewer"]`,
+		"aud":            "a, b,c",
+	}
+
+	assert.Equal(t, "tobi@example.com", c.String("email"))
+	assert.Equal(t, "", c.String("missing"))
+
+	n, ok := c.Number("exp")
+	assert.True(t, ok)
+	assert.Equal(t, 1700000000.0, n)
+
+	_, ok = c.Number("email")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"pets:read", "pets:write"}, c.Scopes())
+	assert.True(t, c.HasScope("pets:write"))
+	assert.False(t, c.HasScope("pets"))
+
+	assert.Equal(t, []string{"admin", "users"}, c.Groups())
+	assert.True(t, c.InGroup("admin"))
+
+	assert.Equal(t, []string{"owner", "viewer"}, c.Strings("roles"))
+	assert.Equal(t, []string{"a", "b", "c"}, c.Strings("aud"))
+	assert.Nil(t, c.Strings("missing"))
+}
+
+func TestClaims_scp(t *testing.T) {
+	c := Claims{"scp": "read write", "groups": "admin,users"}
+	assert.Equal(t, []string{"read", "write"}, c.Scopes())
+	assert.Equal(t, []string{"admin", "users"}, c.Groups())
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestCompression_negotiate(t *testing.T) {
+	z := newCompression(Compression{
+		Encoders: map[string]Encoder{
+			"br": func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+		},
+	})
+
+	cases := map[string]string{
+		"":                               "",
+		"identity":                       "",
+		"gzip":                           "gzip",
+		"GZIP":                           "gzip",
+		"deflate, gzip":                  "gzip",
+		"gzip, deflate, br":              "br",
+		"gzip;q=1.0, br;q=0.5":           "gzip",
+		"br;q=0, gzip;q=0.1":             "gzip",
+		"*":                              "br",
+		"*;q=0.5, deflate":               "deflate",
+		"gzip;q=0, deflate;q=0, *;q=0.1": "br",
+		"compress":                       "",
+	}
+
+	for accept, want := range cases {
+		assert.Equal(t, want, z.negotiate(accept), accept)
+	}
+}
+
+func TestCompression_compress(t *testing.T) {
+	z := newCompression(Compression{MinSize: 10})
+	body := strings.Repeat("hello world ", 10)
+
+	h := http.Header{"Content-Type": {"application/problem+json"}}
+	buf := bytes.NewBufferString(body)
+	assert.True(t, z.compress(h, 200, buf, "gzip, deflate"))
+	assert.Equal(t, "gzip", h.Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", h.Get("Vary"))
+
+	r, err := gzip.NewReader(buf)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(b))
+}
+
+func TestCompression_compress_brotliUnsupported(t *testing.T) {
+	z := newCompression(Compression{MinSize: 10})
+	body := strings.Repeat("hello world ", 10)
+
+	t.Run("only br", func(t *testing.T) {
+		h := http.Header{"Content-Type": {"text/plain"}}
+		buf := bytes.NewBufferString(body)
+		assert.Equal(t, "", z.negotiate("br"))
+		assert.True(t, z.compress(h, 200, buf, "br"))
+		assert.Equal(t, body, buf.String())
+		assert.Equal(t, "", h.Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", h.Get("Vary"))
+	})
+
+	t.Run("br and gzip", func(t *testing.T) {
+		h := http.Header{"Content-Type": {"text/plain"}}
+		buf := bytes.NewBufferString(body)
+		assert.True(t, z.compress(h, 200, buf, "br, gzip"))
+		assert.Equal(t, "gzip", h.Get("Content-Encoding"))
+	})
+}
+
+func TestCompression_compress_skipped(t *testing.T) {
+	z := newCompression(Compression{MinSize: 10})
+	body := strings.Repeat("hello world ", 10)
+
+	cases := []struct {
+		name   string
+		header http.Header
+		status int
+		body   string
+		vary   bool
+	}{
+		{"small", http.Header{"Content-Type": {"text/plain"}}, 200, "hello", false},
+		{"binary", http.Header{"Content-Type": {"image/png"}}, 200, body, false},
+		{"encoded", http.Header{"Content-Type": {"text/plain"}, "Content-Encoding": {"br"}}, 200, body, false},
+		{"no-transform", http.Header{"Content-Type": {"text/plain"}, "Cache-Control": {"public, no-transform"}}, 200, body, false},
+		{"not modified", http.Header{"Content-Type": {"text/plain"}}, 304, body, false},
+		{"not accepted", http.Header{"Content-Type": {"text/plain"}}, 200, body, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := bytes.NewBufferString(c.body)
+			accept := "gzip"
+			if c.vary {
+				accept = "identity"
+			}
+
+			assert.Equal(t, c.vary, z.compress(c.header, c.status, buf, accept))
+			assert.Equal(t, c.body, buf.String())
+			assert.NotEqual(t, "gzip", c.header.Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestCompression_contentTypes(t *testing.T) {
+	z := newCompression(Compression{ContentTypes: []string{"+json", "image/svg+xml"}})
+
+	assert.True(t, z.compressible(http.Header{"Content-Type": {"application/vnd.api+json"}}))
+	assert.True(t, z.compressible(http.Header{"Content-Type": {"image/svg+xml"}}))
+	assert.False(t, z.compressible(http.Header{"Content-Type": {"text/html"}}))
+}
+
+func TestResponseWriter_compression(t *testing.T) {
+	w := NewResponse()
+	w.compression = newCompression(Compression{MinSize: 1})
+	w.acceptEncoding = "deflate"
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("hello world\n"))
+
+	e := w.End()
+	assert.True(t, e.IsBase64Encoded)
+	assert.Equal(t, "deflate", e.Headers["Content-Encoding"])
+	assert.Equal(t, "Accept-Encoding", e.Headers["Vary"])
+	assert.NotEqual(t, "hello world\n", e.Body)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
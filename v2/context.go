@@ -9,8 +9,34 @@ import (
 // key is the type used for any items added to the request context.
 type key int
 
-// requestContextKey is the key for the api gateway proxy `RequestContext`.
-const requestContextKey key = iota
+const (
+	// requestContextKey is the key for the api gateway proxy `RequestContext`.
+	requestContextKey key = iota
+
+	// albRequestContextKey is the key for the load balancer `RequestContext`.
+	albRequestContextKey
+
+	// functionURLRequestContextKey is the key for the function url `RequestContext`.
+	functionURLRequestContextKey
+
+	// proxyRequestContextKey is the key for the api gateway 1.0 payload `RequestContext`.
+	proxyRequestContextKey
+
+	// routeContextKey is the key for the api gateway route template.
+	routeContextKey
+
+	// stageVariablesContextKey is the key for the api gateway stage variables.
+	stageVariablesContextKey
+
+	// stageConfigContextKey is the key for the stage config bound by WithStageConfig.
+	stageConfigContextKey
+
+	// basePathContextKey is the key for the base path stripped from the request path.
+	basePathContextKey
+
+	// traceContextKey is the key for the x-ray trace header.
+	traceContextKey
+)
 
 // RequestContext returns the APIGatewayV2HTTPRequestContext value stored in ctx.
 func RequestContext(ctx context.Context) (events.APIGatewayV2HTTPRequestContext, bool) {
@@ -20,5 +46,96 @@ func RequestContext(ctx context.Context) (events.APIGatewayV2HTTPRequestContext,
 
 // newContext returns a new Context with specific api gateway v2 values.
 func newContext(ctx context.Context, e events.APIGatewayV2HTTPRequest) context.Context {
-	return context.WithValue(ctx, requestContextKey, e.RequestContext)
+	ctx = context.WithValue(ctx, requestContextKey, e.RequestContext)
+	ctx = withStageVariables(ctx, e.StageVariables)
+	return withRoute(ctx, e.RouteKey)
+}
+
+// Authentication returns the mutual TLS authentication details of the request stored in ctx,
+// ok is false when the request was not made to a custom domain requiring mutual TLS.
+func Authentication(ctx context.Context) (events.APIGatewayV2HTTPRequestContextAuthentication, bool) {
+	c, ok := RequestContext(ctx)
+	if !ok || c.Authentication.ClientCert.ClientCertPem == "" {
+		return events.APIGatewayV2HTTPRequestContextAuthentication{}, false
+	}
+
+	return c.Authentication, true
+}
+
+// ALBRequestContext returns the ALBTargetGroupRequestContext value stored in ctx.
+func ALBRequestContext(ctx context.Context) (events.ALBTargetGroupRequestContext, bool) {
+	c, ok := ctx.Value(albRequestContextKey).(events.ALBTargetGroupRequestContext)
+	return c, ok
+}
+
+// newALBContext returns a new Context with specific load balancer values.
+func newALBContext(ctx context.Context, e events.ALBTargetGroupRequest) context.Context {
+	return context.WithValue(ctx, albRequestContextKey, e.RequestContext)
+}
+
+// FunctionURLRequestContext returns the LambdaFunctionURLRequestContext value stored in ctx.
+func FunctionURLRequestContext(ctx context.Context) (events.LambdaFunctionURLRequestContext, bool) {
+	c, ok := ctx.Value(functionURLRequestContextKey).(events.LambdaFunctionURLRequestContext)
+	return c, ok
+}
+
+// FunctionURLIAMIdentity returns the IAM caller identity of a Function URL using
+// the AWS_IAM auth type, ok is false when the request was not signed.
+func FunctionURLIAMIdentity(ctx context.Context) (events.LambdaFunctionURLRequestContextAuthorizerIAMDescription, bool) {
+	c, ok := FunctionURLRequestContext(ctx)
+	if !ok || c.Authorizer == nil || c.Authorizer.IAM == nil {
+		return events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{}, false
+	}
+
+	return *c.Authorizer.IAM, true
+}
+
+// newFunctionURLContext returns a new Context with specific function url values.
+func newFunctionURLContext(ctx context.Context, e events.LambdaFunctionURLRequest) context.Context {
+	return context.WithValue(ctx, functionURLRequestContextKey, e.RequestContext)
+}
+
+// ProxyRequestContext returns the APIGatewayProxyRequestContext value stored in ctx.
+func ProxyRequestContext(ctx context.Context) (events.APIGatewayProxyRequestContext, bool) {
+	c, ok := ctx.Value(proxyRequestContextKey).(events.APIGatewayProxyRequestContext)
+	return c, ok
+}
+
+// newProxyContext returns a new Context with specific api gateway 1.0 payload values.
+func newProxyContext(ctx context.Context, e events.APIGatewayProxyRequest) context.Context {
+	ctx = context.WithValue(ctx, proxyRequestContextKey, e.RequestContext)
+	ctx = withStageVariables(ctx, e.StageVariables)
+	return withRoute(ctx, e.Resource)
+}
+
+// RouteTemplate returns the api gateway route template stored in ctx, this is the
+// route key such as "GET /pets/{id}" or "$default" for HTTP APIs and Function URLs,
+// and the resource such as "/pets/{id}" for 1.0 payloads.
+func RouteTemplate(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(routeContextKey).(string)
+	return v, ok
+}
+
+// withRoute returns a new Context with the route template, if any.
+func withRoute(ctx context.Context, route string) context.Context {
+	if route == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, routeContextKey, route)
+}
+
+// StageVariables returns the api gateway stage variables stored in ctx.
+func StageVariables(ctx context.Context) (map[string]string, bool) {
+	v, ok := ctx.Value(stageVariablesContextKey).(map[string]string)
+	return v, ok
+}
+
+// withStageVariables returns a new Context with the stage variables, if any.
+func withStageVariables(ctx context.Context, vars map[string]string) context.Context {
+	if vars == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, stageVariablesContextKey, vars)
 }
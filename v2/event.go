@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// eventKind is the shape of a Lambda HTTP event payload.
+type eventKind int
+
+const (
+	// eventHTTP is an HTTP API 2.0 payload format event.
+	eventHTTP eventKind = iota
+
+	// eventProxy is a REST API or HTTP API 1.0 payload format event.
+	eventProxy
+
+	// eventALB is an Application Load Balancer target group event.
+	eventALB
+
+	// eventFunctionURL is a Lambda Function URL event.
+	eventFunctionURL
+)
+
+// eventProbe holds the fields used to tell the event shapes apart.
+type eventProbe struct {
+	Version        string  `json:"version"`
+	RouteKey       *string `json:"routeKey"`
+	RawPath        *string `json:"rawPath"`
+	Path           *string `json:"path"`
+	HTTPMethod     string  `json:"httpMethod"`
+	RequestContext struct {
+		ELB        json.RawMessage `json:"elb"`
+		HTTP       json.RawMessage `json:"http"`
+		DomainName string          `json:"domainName"`
+	} `json:"requestContext"`
+}
+
+// detectEvent returns the kind of event the payload represents.
+func detectEvent(payload []byte) (eventKind, error) {
+	var p eventProbe
+
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case p.RequestContext.ELB != nil:
+		return eventALB, nil
+	case p.HTTPMethod != "" || p.Path != nil || p.Version == "1.0" && p.RawPath == nil:
+		return eventProxy, nil
+	case strings.Contains(p.RequestContext.DomainName, ".lambda-url."):
+		return eventFunctionURL, nil
+	case p.RawPath != nil || p.RouteKey != nil || p.RequestContext.HTTP != nil:
+		return eventHTTP, nil
+	default:
+		return 0, errors.New("unsupported event, expected an API Gateway, ALB or Function URL payload")
+	}
+}
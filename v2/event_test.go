@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestDetectEvent(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload string
+		kind    eventKind
+	}{
+		{"rest", `{"resource": "/{proxy+}", "path": "/pets", "httpMethod": "GET", "requestContext": {"stage": "prod"}}`, eventProxy},
+		{"http 1.0", `{"version": "1.0", "resource": "$default", "path": "/pets", "httpMethod": "GET", "requestContext": {"stage": "$default"}}`, eventProxy},
+		{"http 2.0", `{"version": "2.0", "routeKey": "GET /pets", "rawPath": "/pets", "requestContext": {"stage": "$default", "http": {"method": "GET"}}}`, eventHTTP},
+		{"alb", `{"httpMethod": "GET", "path": "/pets", "requestContext": {"elb": {"targetGroupArn": "arn:tg"}}}`, eventALB},
+		{"function url", `{"version": "2.0", "routeKey": "$default", "rawPath": "/pets", "requestContext": {"domainName": "abc.lambda-url.us-east-1.on.aws", "http": {"method": "GET"}}}`, eventFunctionURL},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			kind, err := detectEvent([]byte(c.payload))
+			assert.NoError(t, err)
+			assert.Equal(t, c.kind, kind)
+		})
+	}
+}
+
+func TestDetectEvent_unsupported(t *testing.T) {
+	_, err := detectEvent([]byte(`{"Records": []}`))
+	assert.EqualError(t, err, "unsupported event, expected an API Gateway, ALB or Function URL payload")
+
+	_, err = detectEvent([]byte(`nope`))
+	assert.Error(t, err)
+}
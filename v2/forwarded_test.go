@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestSetConnection(t *testing.T) {
+	t.Run("https", func(t *testing.T) {
+		r, err := http.NewRequest("GET", "//example.com/pets", nil)
+		assert.NoError(t, err)
+		r.Host = "example.com"
+
+		setConnection(r, "HTTP/2.0", "2001:db8::1")
+		assert.Equal(t, "https://example.com/pets", r.URL.String())
+		assert.NotNil(t, r.TLS)
+		assert.Equal(t, "example.com", r.TLS.ServerName)
+		assert.Equal(t, "HTTP/2.0", r.Proto)
+		assert.Equal(t, 2, r.ProtoMajor)
+		assert.Equal(t, 0, r.ProtoMinor)
+		assert.Equal(t, "[2001:db8::1]:0", r.RemoteAddr)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		r, err := http.NewRequest("GET", "//example.com/pets", nil)
+		assert.NoError(t, err)
+		r.Header.Set("X-Forwarded-Proto", "http")
+
+		setConnection(r, "", "")
+		assert.Equal(t, "http://example.com/pets", r.URL.String())
+		assert.Nil(t, r.TLS)
+		assert.Equal(t, "HTTP/1.1", r.Proto)
+		assert.Equal(t, "", r.RemoteAddr)
+	})
+}
+
+func TestConfig_forwardedFor(t *testing.T) {
+	cases := []struct {
+		name    string
+		proxies []string
+		addr    string
+		xff     []string
+		want    string
+	}{
+		{
+			name: "untrusted",
+			addr: "10.0.0.1:0",
+			xff:  []string{"1.2.3.4"},
+			want: "10.0.0.1:0",
+		},
+		{
+			name:    "trusted peer",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "10.0.0.1:0",
+			xff:     []string{"1.2.3.4"},
+			want:    "1.2.3.4:0",
+		},
+		{
+			name:    "trusted chain",
+			proxies: []string{"10.0.0.0/8", "192.168.1.1"},
+			addr:    "10.0.0.1:0",
+			xff:     []string{"6.6.6.6, 1.2.3.4, 192.168.1.1", "10.0.0.2"},
+			want:    "1.2.3.4:0",
+		},
+		{
+			name:    "peer appended",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "10.0.0.1:0",
+			xff:     []string{"1.2.3.4, 10.0.0.1"},
+			want:    "1.2.3.4:0",
+		},
+		{
+			name:    "all trusted",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "10.0.0.1:0",
+			xff:     []string{"10.0.0.3, 10.0.0.2"},
+			want:    "10.0.0.3:0",
+		},
+		{
+			name:    "untrusted peer",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "1.2.3.4:0",
+			xff:     []string{"6.6.6.6"},
+			want:    "1.2.3.4:0",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var conf config
+			WithTrustedProxies(c.proxies...)(&conf)
+
+			r, err := http.NewRequest("GET", "/", nil)
+			assert.NoError(t, err)
+			r.RemoteAddr = c.addr
+			for _, v := range c.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			r = conf.forwardedFor(r)
+			assert.Equal(t, c.want, r.RemoteAddr)
+		})
+	}
+}
+
+func TestWithTrustedProxies_invalid(t *testing.T) {
+	assert.Panics(t, func() {
+		WithTrustedProxies("10.0.0.0/33")
+	})
+
+	assert.Panics(t, func() {
+		WithTrustedProxies("proxy.example.com")
+	})
+}
@@ -0,0 +1,210 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// FunctionURLDomain is the domain name of events encoded by FunctionURLEventFromRequest
+// for requests whose host is not a Function URL, so they are detected as Function URL events.
+const FunctionURLDomain = "abcdefghijklmnopqrstuvwxyz012345.lambda-url.us-east-1.on.aws"
+
+// NewFunctionURLGateway creates a gateway for Lambda Function URLs using the provided http.Handler.
+func NewFunctionURLGateway(h http.Handler, options ...Option) *FunctionURLGateway {
+	gw := &FunctionURLGateway{h: h}
+	for _, o := range options {
+		o(&gw.config)
+	}
+	return gw
+}
+
+// FunctionURLGateway wrap a http handler to enable use as a lambda.Handler behind a Lambda Function URL.
+type FunctionURLGateway struct {
+	h http.Handler
+	config
+}
+
+// Invoke Handler implementation
+func (gw *FunctionURLGateway) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var evt events.LambdaFunctionURLRequest
+
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return []byte{}, err
+	}
+
+	r, err := NewFunctionURLRequest(ctx, evt)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	r, err = gw.prepare(r)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	w := NewFunctionURLResponse()
+	gw.initWriter(&w.ResponseWriter, r)
+	gw.serve(gw.h, &w.ResponseWriter, r)
+
+	resp := w.End()
+
+	return json.Marshal(&resp)
+}
+
+// NewFunctionURLRequest returns a new http.Request from the given Lambda Function URL event.
+//
+// Function URL events share the HTTP API 2.0 payload format, so the request is decoded by
+// NewRequest and both RequestContext and FunctionURLRequestContext are available to handlers.
+func NewFunctionURLRequest(ctx context.Context, e events.LambdaFunctionURLRequest) (*http.Request, error) {
+	req, err := NewRequest(ctx, functionURLToV2(e))
+	if err != nil {
+		return nil, err
+	}
+
+	// function urls have no stage
+	req.Header.Del("X-Stage")
+
+	// host
+	if req.Host == "" {
+		req.URL.Host = e.RequestContext.DomainName
+		req.Host = req.URL.Host
+		if req.TLS != nil {
+			req.TLS.ServerName = req.Host
+		}
+	}
+
+	// custom context values
+	req = req.WithContext(newFunctionURLContext(req.Context(), e))
+
+	return req, nil
+}
+
+// functionURLToV2 returns the HTTP API 2.0 equivalent of a Function URL event.
+func functionURLToV2(e events.LambdaFunctionURLRequest) events.APIGatewayV2HTTPRequest {
+	c := e.RequestContext
+
+	v2 := events.APIGatewayV2HTTPRequest{
+		Version:               e.Version,
+		RouteKey:              "$default",
+		RawPath:               e.RawPath,
+		RawQueryString:        e.RawQueryString,
+		Cookies:               e.Cookies,
+		Headers:               e.Headers,
+		QueryStringParameters: e.QueryStringParameters,
+		Body:                  e.Body,
+		IsBase64Encoded:       e.IsBase64Encoded,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:     "$default",
+			AccountID:    c.AccountID,
+			RequestID:    c.RequestID,
+			APIID:        c.APIID,
+			DomainName:   c.DomainName,
+			DomainPrefix: c.DomainPrefix,
+			Time:         c.Time,
+			TimeEpoch:    c.TimeEpoch,
+			HTTP:         events.APIGatewayV2HTTPRequestContextHTTPDescription(c.HTTP),
+		},
+	}
+
+	if c.Authorizer != nil && c.Authorizer.IAM != nil {
+		v2.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+			IAM: &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{
+				AccessKey: c.Authorizer.IAM.AccessKey,
+				AccountID: c.Authorizer.IAM.AccountID,
+				CallerID:  c.Authorizer.IAM.CallerID,
+				UserARN:   c.Authorizer.IAM.UserARN,
+				UserID:    c.Authorizer.IAM.UserID,
+			},
+		}
+	}
+
+	return v2
+}
+
+// FunctionURLEventFromRequest returns the event a Lambda Function URL sends for the
+// request, consuming its body. Function URL events share the HTTP API 2.0 payload
+// format, so the request is encoded as with EventFromRequest. The domain name is
+// FunctionURLDomain unless the request's host is a Function URL, the Host header
+// field is kept.
+func FunctionURLEventFromRequest(r *http.Request) (events.LambdaFunctionURLRequest, error) {
+	v2, err := EventFromRequest(r)
+	if err != nil {
+		return events.LambdaFunctionURLRequest{}, err
+	}
+
+	c := v2.RequestContext
+	if !strings.Contains(c.DomainName, ".lambda-url.") {
+		c.DomainName = FunctionURLDomain
+		c.DomainPrefix = strings.SplitN(FunctionURLDomain, ".", 2)[0]
+	}
+
+	return events.LambdaFunctionURLRequest{
+		Version:               v2.Version,
+		RawPath:               v2.RawPath,
+		RawQueryString:        v2.RawQueryString,
+		Cookies:               v2.Cookies,
+		Headers:               v2.Headers,
+		QueryStringParameters: v2.QueryStringParameters,
+		Body:                  v2.Body,
+		IsBase64Encoded:       v2.IsBase64Encoded,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			RequestID:    c.RequestID,
+			APIID:        c.DomainPrefix,
+			DomainName:   c.DomainName,
+			DomainPrefix: c.DomainPrefix,
+			Time:         c.Time,
+			TimeEpoch:    c.TimeEpoch,
+			HTTP:         events.LambdaFunctionURLRequestContextHTTPDescription(c.HTTP),
+		},
+	}, nil
+}
+
+// FunctionURLResponseFromEvent returns the http.Response a Lambda Function URL sends for
+// the response event, decoding base64 encoded bodies and setting a Set-Cookie header field
+// for each cookie. The Request field of the response is nil.
+func FunctionURLResponseFromEvent(e events.LambdaFunctionURLResponse) (*http.Response, error) {
+	return ResponseFromEvent(events.APIGatewayV2HTTPResponse{
+		StatusCode:      e.StatusCode,
+		Headers:         e.Headers,
+		Body:            e.Body,
+		IsBase64Encoded: e.IsBase64Encoded,
+		Cookies:         e.Cookies,
+	})
+}
+
+// FunctionURLResponseWriter implements the http.ResponseWriter interface
+// in order to support the Lambda Function URL "protocol".
+type FunctionURLResponseWriter struct {
+	ResponseWriter
+}
+
+// NewFunctionURLResponse returns a new response writer to capture http output for a Function URL.
+func NewFunctionURLResponse() *FunctionURLResponseWriter {
+	return &FunctionURLResponseWriter{
+		ResponseWriter: ResponseWriter{
+			closeNotifyCh: make(chan bool, 1),
+		},
+	}
+}
+
+// End the request.
+func (w *FunctionURLResponseWriter) End() events.LambdaFunctionURLResponse {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	var out events.LambdaFunctionURLResponse
+	out.Body, out.IsBase64Encoded = w.body()
+	out.StatusCode = w.out.StatusCode
+	out.Headers = foldHeaders(w.header)
+	out.Cookies = w.header["Set-Cookie"]
+
+	// notify end
+	w.closeNotifyCh <- true
+
+	return out
+}
@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestNewFunctionURLRequest(t *testing.T) {
+	e := events.LambdaFunctionURLRequest{
+		Version:        "2.0",
+		RawPath:        "/pets/luna",
+		RawQueryString: "order=desc",
+		Cookies:        []string{"a=1"},
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			RequestID:  "1234",
+			DomainName: "abc.lambda-url.us-east-1.on.aws",
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:   "PUT",
+				SourceIP: "1.2.3.4",
+			},
+			Authorizer: &events.LambdaFunctionURLRequestContextAuthorizerDescription{
+				IAM: &events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{
+					AccountID: "111122223333",
+					UserARN:   "arn:aws:iam::111122223333:user/tobi",
+				},
+			},
+		},
+	}
+
+	r, err := NewFunctionURLRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "PUT", r.Method)
+	assert.Equal(t, `/pets/luna?order=desc`, r.RequestURI)
+	assert.Equal(t, `abc.lambda-url.us-east-1.on.aws`, r.Host)
+	assert.Equal(t, `1.2.3.4:0`, r.RemoteAddr)
+	assert.Equal(t, `1234`, r.Header.Get("X-Request-Id"))
+	assert.Equal(t, `a=1`, r.Header.Get("Cookie"))
+	_, ok := r.Header["X-Stage"]
+	assert.False(t, ok)
+
+	c, ok := FunctionURLRequestContext(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "1234", c.RequestID)
+
+	id, ok := FunctionURLIAMIdentity(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "arn:aws:iam::111122223333:user/tobi", id.UserARN)
+
+	v2, ok := RequestContext(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "111122223333", v2.Authorizer.IAM.AccountID)
+}
+
+func TestFunctionURLIAMIdentity_none(t *testing.T) {
+	r, err := NewFunctionURLRequest(context.Background(), events.LambdaFunctionURLRequest{RawPath: "/"})
+	assert.NoError(t, err)
+
+	_, ok := FunctionURLIAMIdentity(r.Context())
+	assert.False(t, ok)
+}
+
+func TestFunctionURLResponseWriter_End(t *testing.T) {
+	w := NewFunctionURLResponse()
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Origin")
+	w.WriteHeader(201)
+	w.Write([]byte("created"))
+
+	e := w.End()
+	assert.Equal(t, 201, e.StatusCode)
+	assert.Equal(t, "created", e.Body)
+	assert.Equal(t, []string{"a=1", "b=2"}, e.Cookies)
+	assert.Equal(t, "Accept, Origin", e.Headers["Vary"])
+	_, ok := e.Headers["Set-Cookie"]
+	assert.False(t, ok)
+}
+
+func TestFunctionURLGateway_Invoke(t *testing.T) {
+	e := []byte(`{"version": "2.0", "rawPath": "/pets", "headers": {"host": "abc.lambda-url.us-east-1.on.aws"}, "requestContext": {"http": {"method": "GET"}}}`)
+
+	gw := NewFunctionURLGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+
+	var resp events.LambdaFunctionURLResponse
+	assert.NoError(t, json.Unmarshal(payload, &resp))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "abc.lambda-url.us-east-1.on.aws", resp.Body)
+}
+
+func TestFunctionURLEventFromRequest(t *testing.T) {
+	r := httptest.NewRequest("PUT", "https://abc.lambda-url.us-east-1.on.aws/pets?x=1", strings.NewReader("hello"))
+	r.Header.Set("Content-Type", "text/plain")
+	r.Header.Set("Cookie", "a=1")
+
+	e, err := FunctionURLEventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/pets", e.RawPath)
+	assert.Equal(t, "x=1", e.RawQueryString)
+	assert.Equal(t, []string{"a=1"}, e.Cookies)
+	assert.Equal(t, "abc", e.RequestContext.APIID)
+	assert.Equal(t, "PUT", e.RequestContext.HTTP.Method)
+
+	payload, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	kind, err := detectEvent(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, eventFunctionURL, kind)
+
+	req, err := NewFunctionURLRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "abc.lambda-url.us-east-1.on.aws", req.Host)
+	assert.Equal(t, "hello", string(b))
+}
+
+func TestFunctionURLEventFromRequest_domain(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/pets", nil)
+
+	e, err := FunctionURLEventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, FunctionURLDomain, e.RequestContext.DomainName)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz012345", e.RequestContext.APIID)
+	assert.Equal(t, "example.com", e.Headers["host"])
+
+	payload, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	kind, err := detectEvent(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, eventFunctionURL, kind)
+}
+
+func TestFunctionURLResponseFromEvent(t *testing.T) {
+	res, err := FunctionURLResponseFromEvent(events.LambdaFunctionURLResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "text/plain"},
+		Cookies:    []string{"a=1"},
+		Body:       "hello",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"a=1"}, res.Header["Set-Cookie"])
+	assert.Equal(t, "text/plain", res.Header.Get("Content-Type"))
+}
@@ -13,7 +13,11 @@ import (
 // ListenAndServe is a drop-in replacement for
 // http.ListenAndServe for use within AWS Lambda.
 //
-// ListenAndServe always returns a non-nil error.
+// Outside of Lambda it listens on addr, passing each request through
+// the same event encoding and decoding, so the program runs locally
+// without a separate main.
+//
+// ListenAndServe always returns a non-nil error outside of Lambda.
 func ListenAndServe(addr string, h http.Handler) error {
 	if h == nil {
 		h = http.DefaultServeMux
@@ -21,6 +25,10 @@ func ListenAndServe(addr string, h http.Handler) error {
 
 	gw := NewGateway(h)
 
+	if !inLambda() {
+		return http.ListenAndServe(addr, localHandler{invoke: gw.Invoke, mediaTypes: &gw.mediaTypes})
+	}
+
 	lambda.StartHandler(gw)
 
 	return nil
@@ -28,17 +36,45 @@ func ListenAndServe(addr string, h http.Handler) error {
 
 // NewGateway creates a gateway using the provided http.Handler enabling use in existing aws-lambda-go
 // projects
-func NewGateway(h http.Handler) *Gateway {
-	return &Gateway{h: h}
+func NewGateway(h http.Handler, options ...Option) *Gateway {
+	gw := &Gateway{h: h}
+	for _, o := range options {
+		o(&gw.config)
+	}
+	return gw
 }
 
 // Gateway wrap a http handler to enable use as a lambda.Handler
+//
+// The event shape is detected for each invocation, so the same Gateway serves
+// REST APIs, HTTP APIs using either payload format, Application Load Balancers
+// and Lambda Function URLs, responding in the format each of them expects.
 type Gateway struct {
 	h http.Handler
+	config
 }
 
 // Invoke Handler implementation
 func (gw *Gateway) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	kind, err := detectEvent(payload)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	switch kind {
+	case eventProxy:
+		return (&ProxyGateway{h: gw.h, config: gw.config}).Invoke(ctx, payload)
+	case eventALB:
+		return (&ALBGateway{h: gw.h, config: gw.config}).Invoke(ctx, payload)
+	case eventFunctionURL:
+		return (&FunctionURLGateway{h: gw.h, config: gw.config}).Invoke(ctx, payload)
+	default:
+		return gw.invokeHTTP(ctx, payload)
+	}
+}
+
+// invokeHTTP handles HTTP API 2.0 payload format events.
+func (gw *Gateway) invokeHTTP(ctx context.Context, payload []byte) ([]byte, error) {
 	var evt events.APIGatewayV2HTTPRequest
 
 	if err := json.Unmarshal(payload, &evt); err != nil {
@@ -50,8 +86,14 @@ func (gw *Gateway) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
 		return []byte{}, err
 	}
 
+	r, err = gw.prepare(r)
+	if err != nil {
+		return []byte{}, err
+	}
+
 	w := NewResponse()
-	gw.h.ServeHTTP(w, r)
+	gw.initWriter(w, r)
+	gw.serve(gw.h, w, r)
 
 	resp := w.End()
 
@@ -2,9 +2,11 @@ package gateway_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/apex/gateway/v2"
@@ -30,3 +32,135 @@ func TestGateway_Invoke(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, `{"body":"Hello World from Go\n", "cookies": null, "headers":{"Content-Type":"text/plain; charset=utf8"}, "multiValueHeaders":{}, "statusCode":200}`, string(payload))
 }
+
+func TestGateway_Invoke_detect(t *testing.T) {
+	cases := []struct {
+		name     string
+		event    string
+		response string
+	}{
+		{
+			"rest",
+			`{"resource": "/{proxy+}", "path": "/pets/luna", "httpMethod": "GET", "requestContext": {"stage": "prod"}}`,
+			`{"body":"Hello World from Go\n", "headers":{"Content-Type":"text/plain; charset=utf8"}, "multiValueHeaders":{}, "statusCode":200}`,
+		},
+		{
+			"alb",
+			`{"httpMethod": "GET", "path": "/pets/luna", "headers": {}, "requestContext": {"elb": {"targetGroupArn": "arn:tg"}}}`,
+			`{"body":"Hello World from Go\n", "headers":{"Content-Type":"text/plain; charset=utf8"}, "multiValueHeaders":null, "statusCode":200, "statusDescription":"200 OK", "isBase64Encoded":false}`,
+		},
+		{
+			"function url",
+			`{"version": "2.0", "rawPath": "/pets/luna", "requestContext": {"domainName": "abc.lambda-url.us-east-1.on.aws", "http": {"method": "GET"}}}`,
+			`{"body":"Hello World from Go\n", "headers":{"Content-Type":"text/plain; charset=utf8"}, "cookies":null, "statusCode":200, "isBase64Encoded":false}`,
+		},
+	}
+
+	gw := gateway.NewGateway(http.HandlerFunc(hello))
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			payload, err := gw.Invoke(context.Background(), []byte(c.event))
+			assert.NoError(t, err)
+			assert.JSONEq(t, c.response, string(payload))
+		})
+	}
+}
+
+func TestGateway_Invoke_unsupported(t *testing.T) {
+	gw := gateway.NewGateway(http.HandlerFunc(hello))
+
+	_, err := gw.Invoke(context.Background(), []byte(`{"Records": []}`))
+	assert.Error(t, err)
+}
+
+func TestGateway_Invoke_binaryMediaTypes(t *testing.T) {
+	e := []byte(`{"version": "1.0", "httpMethod": "GET", "path": "/pets/luna"}`)
+
+	gw := gateway.NewGateway(http.HandlerFunc(hello), gateway.WithBinaryMediaTypes("*/*"))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"body":"SGVsbG8gV29ybGQgZnJvbSBHbwo=", "headers":{"Content-Type":"text/plain; charset=utf8"}, "multiValueHeaders":{}, "statusCode":200, "isBase64Encoded":true}`, string(payload))
+}
+
+func TestGateway_Invoke_compression(t *testing.T) {
+	cases := []struct {
+		name  string
+		event string
+	}{
+		{
+			"http",
+			`{"version": "2.0", "rawPath": "/pets", "headers": {"accept-encoding": "%s"}, "requestContext": {"http": {"method": "GET"}}}`,
+		},
+		{
+			"function url",
+			`{"version": "2.0", "rawPath": "/pets", "headers": {"accept-encoding": "%s"}, "requestContext": {"domainName": "abc.lambda-url.us-east-1.on.aws", "http": {"method": "GET"}}}`,
+		},
+		{
+			"rest",
+			`{"resource": "/{proxy+}", "path": "/pets", "httpMethod": "GET", "headers": {"Accept-Encoding": "%s"}, "requestContext": {"stage": "prod"}}`,
+		},
+		{
+			"alb",
+			`{"httpMethod": "GET", "path": "/pets", "headers": {"accept-encoding": "%s"}, "requestContext": {"elb": {"targetGroupArn": "arn:tg"}}}`,
+		},
+	}
+
+	accepts := map[string]string{
+		"br, gzip":          "gzip",
+		"gzip;q=0, deflate": "deflate",
+	}
+
+	gw := gateway.NewGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("hello world ", 200)))
+	}), gateway.WithCompression(gateway.Compression{}))
+
+	for _, c := range cases {
+		for accept, want := range accepts {
+			t.Run(c.name+" "+accept, func(t *testing.T) {
+				payload, err := gw.Invoke(context.Background(), []byte(fmt.Sprintf(c.event, accept)))
+				assert.NoError(t, err)
+
+				var res struct {
+					Headers map[string]string `json:"headers"`
+				}
+				assert.NoError(t, json.Unmarshal(payload, &res))
+				assert.Equal(t, want, res.Headers["Content-Encoding"])
+			})
+		}
+	}
+}
+
+func TestGateway_Invoke_payloadLimit(t *testing.T) {
+	e := []byte(`{"version": "1.0", "httpMethod": "GET", "path": "/pets/luna"}`)
+
+	gw := gateway.NewGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, gateway.DefaultMaxPayload))
+	}))
+
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `"statusCode":502`)
+	assert.Contains(t, string(payload), `exceeds the Lambda payload limit of 6291456 bytes`)
+}
+
+func TestGateway_Invoke_stageConfig(t *testing.T) {
+	type config struct {
+		Backend string `stage:"backend" default:"https://api.example.com"`
+	}
+
+	gw := gateway.NewGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, gateway.StageConfig(r.Context()).(*config).Backend)
+	}), gateway.WithStageConfig(&config{}))
+
+	e := []byte(`{"version": "2.0", "rawPath": "/pets", "stageVariables": {"backend": "https://beta.example.com"}, "requestContext": {"http": {"method": "GET"}}}`)
+	payload, err := gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `"body":"https://beta.example.com"`)
+
+	e = []byte(`{"version": "1.0", "httpMethod": "GET", "path": "/pets"}`)
+	payload, err = gw.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `"body":"https://api.example.com"`)
+}
@@ -0,0 +1,190 @@
+package gatewaytest
+
+import (
+	"strings"
+
+	"github.com/apex/gateway/v2"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Fixed identifiers used by ALB and Function URL events.
+const (
+	TargetGroupARN    = "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/lambda/1234567890abcdef"
+	FunctionURLDomain = gateway.FunctionURLDomain
+)
+
+// HTTPEvent returns the HTTP API 2.0 payload format event, header fields
+// are lower-cased and joined with commas and cookies moved to their own field.
+func (r *Request) HTTPEvent() events.APIGatewayV2HTTPRequest {
+	e, err := gateway.EventFromRequest(r.httpRequest())
+	if err != nil {
+		panic("gatewaytest: encoding event: " + err.Error())
+	}
+
+	e.Body, e.IsBase64Encoded = r.encodeBody(e.Body, e.IsBase64Encoded)
+	e.PathParameters = r.pathParameters
+	e.StageVariables = r.stageVariables
+
+	if r.route != "" {
+		e.RouteKey = r.method + " " + r.route
+	}
+
+	c := &e.RequestContext
+	c.RouteKey = e.RouteKey
+	c.AccountID = AccountID
+	c.APIID = APIID
+	c.RequestID = r.requestID
+	c.Authorizer = r.httpAuthorizer()
+	c.Time, c.TimeEpoch = r.timestamp()
+
+	if r.stage != "" {
+		c.Stage = r.stage
+	}
+
+	return e
+}
+
+// httpAuthorizer returns the authorizer description of HTTP API events.
+func (r *Request) httpAuthorizer() *events.APIGatewayV2HTTPRequestContextAuthorizerDescription {
+	var a events.APIGatewayV2HTTPRequestContextAuthorizerDescription
+
+	switch {
+	case r.claims != nil:
+		a.JWT = &events.APIGatewayV2HTTPRequestContextAuthorizerJWTDescription{
+			Claims: r.claims,
+			Scopes: r.scopes,
+		}
+	case r.lambda != nil || r.principalID != "":
+		a.Lambda = r.lambda
+	case r.iam != nil:
+		a.IAM = &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{
+			AccessKey: r.iam.accessKey,
+			AccountID: r.iam.accountID,
+			UserARN:   r.iam.userARN,
+			CognitoIdentity: events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity{
+				IdentityID:     r.iam.identityID,
+				IdentityPoolID: r.iam.identityPoolID,
+			},
+		}
+	default:
+		return nil
+	}
+
+	return &a
+}
+
+// ProxyEvent returns the 1.0 payload format event sent by REST APIs, with both
+// the single and multi-value header fields and query string parameters.
+func (r *Request) ProxyEvent() events.APIGatewayProxyRequest {
+	e, err := gateway.ProxyEventFromRequest(r.httpRequest())
+	if err != nil {
+		panic("gatewaytest: encoding event: " + err.Error())
+	}
+
+	e.Body, e.IsBase64Encoded = r.encodeBody(e.Body, e.IsBase64Encoded)
+	e.StageVariables = r.stageVariables
+
+	if r.route != "" {
+		e.Resource = r.route
+		e.PathParameters = nil
+	}
+
+	if r.pathParameters != nil {
+		e.PathParameters = r.pathParameters
+	}
+
+	c := &e.RequestContext
+	c.ResourcePath = e.Resource
+	c.AccountID = AccountID
+	c.ResourceID = "abc123"
+	c.APIID = APIID
+	c.RequestID = r.requestID
+	c.Authorizer = r.proxyAuthorizer()
+	c.RequestTime, c.RequestTimeEpoch = r.timestamp()
+
+	if r.stage != "" {
+		c.Stage = r.stage
+	}
+
+	if r.iam != nil {
+		id := &c.Identity
+		id.AccountID = r.iam.accountID
+		id.UserArn = r.iam.userARN
+		id.AccessKey = r.iam.accessKey
+		id.CognitoIdentityPoolID = r.iam.identityPoolID
+		id.CognitoIdentityID = r.iam.identityID
+		if r.iam.identityID != "" {
+			id.CognitoAuthenticationType = "authenticated"
+		}
+	}
+
+	return e
+}
+
+// proxyAuthorizer returns the authorizer context of REST API events.
+func (r *Request) proxyAuthorizer() map[string]interface{} {
+	switch {
+	case r.claims != nil:
+		claims := make(map[string]interface{}, len(r.claims)+1)
+		for k, v := range r.claims {
+			claims[k] = v
+		}
+		if _, ok := claims["scope"]; !ok && len(r.scopes) > 0 {
+			claims["scope"] = strings.Join(r.scopes, " ")
+		}
+		return map[string]interface{}{"claims": claims}
+	case r.lambda != nil || r.principalID != "":
+		m := make(map[string]interface{}, len(r.lambda)+1)
+		for k, v := range r.lambda {
+			m[k] = v
+		}
+		m["principalId"] = r.principalID
+		return m
+	default:
+		return nil
+	}
+}
+
+// ALBEvent returns the event sent by an Application Load Balancer, multiValue
+// must match whether the target group has multi-value headers enabled. Header
+// field names are lower-cased and query string parameters remain url encoded.
+func (r *Request) ALBEvent(multiValue bool) events.ALBTargetGroupRequest {
+	e, err := gateway.ALBEventFromRequest(r.httpRequest(), multiValue)
+	if err != nil {
+		panic("gatewaytest: encoding event: " + err.Error())
+	}
+
+	e.Body, e.IsBase64Encoded = r.encodeBody(e.Body, e.IsBase64Encoded)
+	e.RequestContext.ELB.TargetGroupArn = TargetGroupARN
+
+	return e
+}
+
+// FunctionURLEvent returns the event sent by a Lambda Function URL, which shares the
+// HTTP API 2.0 payload format, only supporting IAM authorization. The domain name
+// is FunctionURLDomain unless the request's host is a Function URL.
+func (r *Request) FunctionURLEvent() events.LambdaFunctionURLRequest {
+	e, err := gateway.FunctionURLEventFromRequest(r.httpRequest())
+	if err != nil {
+		panic("gatewaytest: encoding event: " + err.Error())
+	}
+
+	e.Body, e.IsBase64Encoded = r.encodeBody(e.Body, e.IsBase64Encoded)
+
+	c := &e.RequestContext
+	c.AccountID = AccountID
+	c.RequestID = r.requestID
+	c.Time, c.TimeEpoch = r.timestamp()
+
+	if r.iam != nil {
+		c.Authorizer = &events.LambdaFunctionURLRequestContextAuthorizerDescription{
+			IAM: &events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{
+				AccessKey: r.iam.accessKey,
+				AccountID: r.iam.accountID,
+				UserARN:   r.iam.userARN,
+			},
+		}
+	}
+
+	return e
+}
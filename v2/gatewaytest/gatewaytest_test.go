@@ -0,0 +1,259 @@
+package gatewaytest_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apex/gateway/v2"
+	"github.com/apex/gateway/v2/gatewaytest"
+	"github.com/tj/assert"
+)
+
+func Example() {
+	rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := gateway.JWTClaims(r.Context())
+		fmt.Fprintf(w, "Hello %s", claims.String("sub"))
+	}))
+
+	req := gatewaytest.NewRequest("GET", "/pets").JWT(map[string]string{"sub": "tobi"})
+
+	res, _ := rec.Do(context.Background(), req.HTTPEvent())
+	b, _ := ioutil.ReadAll(res.Body)
+	fmt.Println(res.StatusCode, string(b))
+	// Output: 200 Hello tobi
+}
+
+// echo responds with a summary of the request.
+func echo(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Set("X-Method", r.Method)
+	w.Header().Set("X-URL", r.URL.RequestURI())
+	w.Header().Set("X-Host", r.Host)
+	w.Header().Set("X-Remote-Addr", r.RemoteAddr)
+	w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+	w.Header()["X-Cookie"] = r.Header["Cookie"]
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+func TestRecorder_Do(t *testing.T) {
+	req := gatewaytest.NewRequest("POST", "https://api.example.com/pets?species=ferret&species=cat").
+		Header("Content-Type", "image/png").
+		Header("Cookie", "session=abc").
+		Body([]byte("\x89PNG")).
+		SourceIP("1.2.3.4")
+
+	cases := []struct {
+		name  string
+		event interface{}
+		url   string
+	}{
+		{"http", req.HTTPEvent(), "/pets?species=ferret&species=cat"},
+		{"proxy", req.ProxyEvent(), "/pets?species=ferret&species=cat"},
+		{"alb", req.ALBEvent(false), "/pets?species=cat"},
+		{"alb multi-value", req.ALBEvent(true), "/pets?species=ferret&species=cat"},
+		{"function url", req.FunctionURLEvent(), "/pets?species=ferret&species=cat"},
+		{"request", req, "/pets?species=ferret&species=cat"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := gatewaytest.NewRecorder(http.HandlerFunc(echo))
+
+			res, err := rec.Do(context.Background(), c.event)
+			assert.NoError(t, err)
+
+			b, err := ioutil.ReadAll(res.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusCreated, res.StatusCode)
+			assert.Equal(t, "201 Created", res.Status)
+			assert.Equal(t, "\x89PNG", string(b))
+			assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+			assert.Equal(t, "POST", res.Header.Get("X-Method"))
+			assert.Equal(t, c.url, res.Header.Get("X-URL"))
+			assert.Equal(t, "api.example.com", res.Header.Get("X-Host"))
+			assert.Equal(t, "1.2.3.4:0", res.Header.Get("X-Remote-Addr"))
+			assert.Equal(t, "session=abc", res.Header.Get("X-Cookie"))
+			assert.NotEmpty(t, rec.Event)
+			assert.NotEmpty(t, rec.Payload)
+		})
+	}
+}
+
+func TestRecorder_Do_requestID(t *testing.T) {
+	rec := gatewaytest.NewRecorder(http.HandlerFunc(echo))
+
+	res, err := rec.Do(context.Background(), gatewaytest.NewRequest("GET", "/").ProxyEvent())
+	assert.NoError(t, err)
+	assert.Equal(t, gatewaytest.RequestID, res.Header.Get("X-Request-Id"))
+
+	res, err = rec.Do(context.Background(), gatewaytest.NewRequest("GET", "/").RequestID("abc").HTTPEvent())
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", res.Header.Get("X-Request-Id"))
+}
+
+func TestRecorder_Do_unsupported(t *testing.T) {
+	rec := gatewaytest.NewRecorder(http.HandlerFunc(echo))
+
+	_, err := rec.Do(context.Background(), map[string]string{"path": "/"})
+	assert.EqualError(t, err, "unsupported event type map[string]string")
+}
+
+func TestRequest_authorizers(t *testing.T) {
+	t.Run("jwt", func(t *testing.T) {
+		req := gatewaytest.NewRequest("GET", "/").JWT(map[string]string{"sub": "tobi"}, "pets:read")
+
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := gateway.JWTClaims(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "tobi", claims.String("sub"))
+			assert.True(t, claims.HasScope("pets:read"))
+		}))
+
+		_, err := rec.Do(context.Background(), req.HTTPEvent())
+		assert.NoError(t, err)
+
+		_, err = rec.Do(context.Background(), req.ProxyEvent())
+		assert.NoError(t, err)
+	})
+
+	t.Run("lambda", func(t *testing.T) {
+		req := gatewaytest.NewRequest("GET", "/").LambdaAuthorizer("tobi", map[string]interface{}{"tier": "gold"})
+
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m, ok := gateway.LambdaAuthorizerContext(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "gold", m["tier"])
+		}))
+
+		_, err := rec.Do(context.Background(), req.HTTPEvent())
+		assert.NoError(t, err)
+
+		_, err = rec.Do(context.Background(), req.ProxyEvent())
+		assert.NoError(t, err)
+	})
+
+	t.Run("iam", func(t *testing.T) {
+		req := gatewaytest.NewRequest("GET", "/").
+			IAM(gatewaytest.AccountID, "arn:aws:iam::123456789012:user/tobi", "AKIA").
+			CognitoIdentity("us-east-1:pool", "us-east-1:identity")
+
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok := gateway.IAMIdentity(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "arn:aws:iam::123456789012:user/tobi", id.UserARN)
+
+			c, ok := gateway.CognitoIdentity(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "us-east-1:identity", c.IdentityID)
+		}))
+
+		_, err := rec.Do(context.Background(), req.HTTPEvent())
+		assert.NoError(t, err)
+
+		_, err = rec.Do(context.Background(), req.ProxyEvent())
+		assert.NoError(t, err)
+	})
+}
+
+func TestRequest_route(t *testing.T) {
+	req := gatewaytest.NewRequest("GET", "/pets/luna").
+		Route("/pets/{id}").
+		PathParameter("id", "luna").
+		StageVariable("backend", "https://example.com").
+		Stage("beta")
+
+	cases := []struct {
+		event interface{}
+		route string
+	}{
+		{req.HTTPEvent(), "GET /pets/{id}"},
+		{req.ProxyEvent(), "/pets/{id}"},
+	}
+
+	for _, c := range cases {
+		rec := gatewaytest.NewRecorder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, ok := gateway.RouteTemplate(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, c.route, route)
+			assert.Equal(t, "luna", r.PathValue("id"))
+			assert.Equal(t, "beta", r.Header.Get("X-Stage"))
+			vars, _ := gateway.StageVariables(r.Context())
+			assert.Equal(t, "https://example.com", vars["backend"])
+		}))
+
+		_, err := rec.Do(context.Background(), c.event)
+		assert.NoError(t, err)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	r := httptest.NewRequest("PUT", "http://api.example.com/pets/luna?x=1", strings.NewReader(`{"name":"Luna"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	e := gatewaytest.FromRequest(r).HTTPEvent()
+	assert.Equal(t, "PUT", e.RequestContext.HTTP.Method)
+	assert.Equal(t, "/pets/luna", e.RawPath)
+	assert.Equal(t, "x=1", e.RawQueryString)
+	assert.Equal(t, "api.example.com", e.Headers["host"])
+	assert.Equal(t, "application/json", e.Headers["content-type"])
+	assert.Equal(t, "192.0.2.1", e.RequestContext.HTTP.SourceIP)
+	assert.Equal(t, `{"name":"Luna"}`, e.Body)
+	assert.False(t, e.IsBase64Encoded)
+
+	b, err := ioutil.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Luna"}`, string(b))
+}
+
+func TestRequest_JSON(t *testing.T) {
+	e := gatewaytest.NewRequest("POST", "/pets").JSON(map[string]string{"name": "Tobi"}).ProxyEvent()
+	assert.Equal(t, `{"name":"Tobi"}`, e.Body)
+	assert.Equal(t, "application/json", e.Headers["Content-Type"])
+	assert.Equal(t, "15", e.Headers["Content-Length"])
+}
+
+func TestRequest_Binary(t *testing.T) {
+	e := gatewaytest.NewRequest("POST", "/pets").Body([]byte("hello")).Binary().HTTPEvent()
+	assert.Equal(t, "aGVsbG8=", e.Body)
+	assert.True(t, e.IsBase64Encoded)
+}
+
+func TestRequest_defaults(t *testing.T) {
+	req := gatewaytest.NewRequest("GET", "/pets?species=ferret")
+
+	h := req.HTTPEvent()
+	assert.Equal(t, "$default", h.RouteKey)
+	assert.Equal(t, "$default", h.RequestContext.Stage)
+	assert.Equal(t, gatewaytest.RequestID, h.RequestContext.RequestID)
+	assert.Equal(t, "192.0.2.1", h.RequestContext.HTTP.SourceIP)
+	assert.Equal(t, "https", h.Headers["x-forwarded-proto"])
+	assert.Equal(t, "443", h.Headers["x-forwarded-port"])
+	assert.Equal(t, "192.0.2.1", h.Headers["x-forwarded-for"])
+
+	p := req.ProxyEvent()
+	assert.Equal(t, "/{proxy+}", p.Resource)
+	assert.Equal(t, map[string]string{"proxy": "pets"}, p.PathParameters)
+	assert.Equal(t, "$default", p.RequestContext.Stage)
+	assert.Equal(t, "example", p.RequestContext.DomainPrefix)
+	assert.Equal(t, gatewaytest.RequestID, p.RequestContext.RequestID)
+	assert.Equal(t, "192.0.2.1", p.RequestContext.Identity.SourceIP)
+	assert.Equal(t, map[string]string{"species": "ferret"}, p.QueryStringParameters)
+
+	a := req.ALBEvent(false)
+	assert.Equal(t, gatewaytest.TargetGroupARN, a.RequestContext.ELB.TargetGroupArn)
+	assert.Equal(t, "https", a.Headers["x-forwarded-proto"])
+
+	f := req.FunctionURLEvent()
+	assert.Equal(t, gatewaytest.FunctionURLDomain, f.RequestContext.DomainName)
+	assert.Equal(t, "example.com", f.Headers["host"])
+}
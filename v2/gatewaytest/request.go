@@ -0,0 +1,275 @@
+// Package gatewaytest provides utilities for testing handlers served by the gateway,
+// building the events sent by API Gateway, Application Load Balancers and
+// Lambda Function URLs, and decoding the responses of a Gateway.
+package gatewaytest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Fixed identifiers used by the events, matching the AWS documentation samples.
+const (
+	AccountID = "123456789012"
+	APIID     = "1234567890"
+	RequestID = "c6af9ac6-7b61-11e6-9a41-93e8deadbeef"
+)
+
+// Request is a builder of the Lambda events sent for an HTTP request,
+// its methods return the Request so calls may be chained. Events are encoded
+// by the gateway's conversions, such as gateway.EventFromRequest, with the
+// authorizers, routes, stage and fixed identifiers of the Request layered on top.
+type Request struct {
+	method         string
+	url            *url.URL
+	header         http.Header
+	body           []byte
+	binary         bool
+	sourceIP       string
+	requestID      string
+	stage          string
+	route          string
+	pathParameters map[string]string
+	stageVariables map[string]string
+	time           time.Time
+
+	claims      map[string]string
+	scopes      []string
+	principalID string
+	lambda      map[string]interface{}
+	iam         *iam
+}
+
+// iam is the caller of a request using IAM authorization.
+type iam struct {
+	accountID      string
+	userARN        string
+	accessKey      string
+	identityPoolID string
+	identityID     string
+}
+
+// NewRequest returns a Request for the method and target, which is either a path
+// with an optional query string or an absolute URL. The host defaults to
+// "example.com" and the client address to "192.0.2.1", as with httptest.NewRequest.
+//
+// NewRequest panics if the target is not a valid URL.
+func NewRequest(method, target string) *Request {
+	u, err := url.Parse(target)
+	if err != nil {
+		panic("gatewaytest: invalid target: " + err.Error())
+	}
+
+	if u.Host == "" {
+		u.Host = "example.com"
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return &Request{
+		method:    method,
+		url:       u,
+		header:    make(http.Header),
+		sourceIP:  "192.0.2.1",
+		requestID: RequestID,
+		time:      time.Now(),
+	}
+}
+
+// FromRequest returns a Request for the method, URL, header fields, body and
+// client address of r, reading its body.
+func FromRequest(r *http.Request) *Request {
+	target := r.URL.String()
+	if r.Host != "" {
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = r.Host
+		target = u.String()
+	}
+
+	req := NewRequest(r.Method, target)
+	req.header = r.Header.Clone()
+
+	if r.Body != nil {
+		req.body, _ = ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(req.body))
+	}
+
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		req.sourceIP = ip
+	}
+
+	return req
+}
+
+// Header adds the header field.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+// Body sets the body.
+func (r *Request) Body(b []byte) *Request {
+	r.body = b
+	return r
+}
+
+// JSON sets the body to the JSON encoding of v, and the Content-Type header field.
+func (r *Request) JSON(v interface{}) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic("gatewaytest: encoding json body: " + err.Error())
+	}
+
+	r.header.Set("Content-Type", "application/json")
+	return r.Body(b)
+}
+
+// Binary marks the body as binary so it is base64 encoded, as API Gateway does for
+// binary media types. Bodies which are not valid UTF-8 are always base64 encoded.
+func (r *Request) Binary() *Request {
+	r.binary = true
+	return r
+}
+
+// SourceIP sets the client address.
+func (r *Request) SourceIP(ip string) *Request {
+	r.sourceIP = ip
+	return r
+}
+
+// RequestID sets the request id, RequestID by default.
+func (r *Request) RequestID(id string) *Request {
+	r.requestID = id
+	return r
+}
+
+// Stage sets the stage name, "$default" by default.
+func (r *Request) Stage(name string) *Request {
+	r.stage = name
+	return r
+}
+
+// StageVariable sets a stage variable.
+func (r *Request) StageVariable(key, value string) *Request {
+	if r.stageVariables == nil {
+		r.stageVariables = make(map[string]string)
+	}
+
+	r.stageVariables[key] = value
+	return r
+}
+
+// Route sets the resource path template, such as "/pets/{id}", which defaults to
+// a greedy "/{proxy+}" resource for REST APIs and the $default route for HTTP APIs.
+func (r *Request) Route(template string) *Request {
+	r.route = template
+	return r
+}
+
+// PathParameter sets a path parameter resolved from the route.
+func (r *Request) PathParameter(key, value string) *Request {
+	if r.pathParameters == nil {
+		r.pathParameters = make(map[string]string)
+	}
+
+	r.pathParameters[key] = value
+	return r
+}
+
+// Time sets the time of the request, the current time by default.
+func (r *Request) Time(t time.Time) *Request {
+	r.time = t
+	return r
+}
+
+// JWT sets the claims and scopes of a JWT or Cognito user pool authorizer.
+func (r *Request) JWT(claims map[string]string, scopes ...string) *Request {
+	r.claims = claims
+	r.scopes = scopes
+	return r
+}
+
+// LambdaAuthorizer sets the principal and context returned by a Lambda authorizer.
+func (r *Request) LambdaAuthorizer(principalID string, context map[string]interface{}) *Request {
+	r.principalID = principalID
+	r.lambda = context
+	return r
+}
+
+// IAM sets the caller of a request using IAM authorization.
+func (r *Request) IAM(accountID, userARN, accessKey string) *Request {
+	if r.iam == nil {
+		r.iam = &iam{}
+	}
+
+	r.iam.accountID = accountID
+	r.iam.userARN = userARN
+	r.iam.accessKey = accessKey
+	return r
+}
+
+// CognitoIdentity sets the caller of a request signed with Amazon Cognito identity pool credentials.
+func (r *Request) CognitoIdentity(identityPoolID, identityID string) *Request {
+	if r.iam == nil {
+		r.iam = &iam{accountID: AccountID}
+	}
+
+	r.iam.identityPoolID = identityPoolID
+	r.iam.identityID = identityID
+	return r
+}
+
+// httpRequest returns the request converted into events by the gateway, made over
+// HTTPS from the source address with the Content-Length header field set.
+func (r *Request) httpRequest() *http.Request {
+	u := *r.url
+	u.Scheme = "https"
+
+	h := r.header.Clone()
+	var body io.ReadCloser = http.NoBody
+	if len(r.body) > 0 {
+		body = ioutil.NopCloser(bytes.NewReader(r.body))
+		if h.Get("Content-Length") == "" {
+			h.Set("Content-Length", strconv.Itoa(len(r.body)))
+		}
+	}
+
+	return &http.Request{
+		Method:     r.method,
+		URL:        &u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     h,
+		Body:       body,
+		Host:       u.Host,
+		RemoteAddr: net.JoinHostPort(r.sourceIP, "1234"),
+	}
+}
+
+// encodeBody returns the event body, base64 encoded when the request is binary
+// or the body is not valid UTF-8 and the conversion left it as text.
+func (r *Request) encodeBody(body string, encoded bool) (string, bool) {
+	if !encoded && (r.binary || !utf8.Valid(r.body)) {
+		return base64.StdEncoding.EncodeToString(r.body), true
+	}
+
+	return body, encoded
+}
+
+// timestamp returns the request time in the request context format, and in milliseconds.
+func (r *Request) timestamp() (string, int64) {
+	return r.time.UTC().Format("02/Jan/2006:15:04:05 -0700"), r.time.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// inLambda returns true when running under the Lambda runtime.
+func inLambda() bool {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" || os.Getenv("_LAMBDA_SERVER_PORT") != ""
+}
+
+// localHandler serves HTTP requests outside of Lambda by encoding them as
+// HTTP API 2.0 events and decoding the responses, so requests take the same
+// path as they do in production.
+type localHandler struct {
+	invoke     func(context.Context, []byte) ([]byte, error)
+	mediaTypes *mediaTypes
+}
+
+// ServeHTTP implementation.
+func (h localHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, err := eventFromRequest(r, h.mediaTypes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// API Gateway responds with 500 when the function fails
+	out, err := h.invoke(r.Context(), payload)
+	if err != nil {
+		localError(w)
+		return
+	}
+
+	var resp events.APIGatewayV2HTTPResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		localError(w)
+		return
+	}
+
+	res, err := ResponseFromEvent(resp)
+	if err != nil {
+		localError(w)
+		return
+	}
+
+	for k, v := range res.Header {
+		w.Header()[k] = v
+	}
+
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, res.Body)
+}
+
+// localError responds as API Gateway does when the function fails.
+func localError(w http.ResponseWriter) {
+	body := `{"message":"Internal Server Error"}`
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte(body))
+}
@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestMediaTypes_isBinary_defaults(t *testing.T) {
+	var m *mediaTypes
+
+	text := []string{
+		"text/html",
+		"application/json",
+		"application/problem+json",
+		"application/vnd.api+json; charset=utf-8",
+		"application/atom+xml",
+		"image/svg+xml",
+		"application/x-www-form-urlencoded",
+		"application/graphql",
+	}
+
+	for _, kind := range text {
+		assert.False(t, m.isBinary(kind), kind)
+	}
+
+	binary := []string{
+		"image/png",
+		"application/octet-stream",
+		"application/cbor",
+		"application/vnd.ms-excel",
+		"",
+		"not a media type",
+	}
+
+	for _, kind := range binary {
+		assert.True(t, m.isBinary(kind), kind)
+	}
+}
+
+func TestMediaTypes_isBinary_registered(t *testing.T) {
+	var m mediaTypes
+	m.add(true, "application/vnd.api+json", "+xml", "text/csv")
+	m.add(false, "application/cbor", "image/*")
+
+	assert.True(t, m.isBinary("application/vnd.api+json"))
+	assert.False(t, m.isBinary("application/problem+json"))
+	assert.True(t, m.isBinary("application/atom+xml"))
+	assert.True(t, m.isBinary("text/csv"))
+	assert.False(t, m.isBinary("text/html"))
+	assert.False(t, m.isBinary("application/cbor"))
+	assert.False(t, m.isBinary("image/png"))
+}
+
+func TestMediaTypes_isBinary_all(t *testing.T) {
+	var m mediaTypes
+	m.add(true, "*/*")
+	m.add(false, "application/json")
+
+	assert.True(t, m.isBinary("text/plain"))
+	assert.True(t, m.isBinary("application/problem+json"))
+	assert.False(t, m.isBinary("application/json"))
+}
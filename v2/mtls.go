@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// setClientCert decodes the PEM encoded client certificate chain of a mutual TLS
+// request into the request's TLS state. API Gateway has verified the chain against
+// the domain's truststore, so it is also recorded as verified.
+func setClientCert(req *http.Request, data string) error {
+	if data == "" {
+		return nil
+	}
+
+	var certs []*x509.Certificate
+	rest := []byte(data)
+
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return errors.Wrap(err, "parsing client certificate")
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return errors.New("parsing client certificate: no PEM encoded certificate found")
+	}
+
+	if req.TLS == nil {
+		req.TLS = &tls.ConnectionState{
+			HandshakeComplete: true,
+			ServerName:        req.Host,
+		}
+	}
+
+	req.TLS.PeerCertificates = certs
+	req.TLS.VerifiedChains = [][]*x509.Certificate{certs}
+	return nil
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+// newClientCert returns a PEM encoded self-signed client certificate.
+func newClientCert(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client.example.com"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestNewRequest_clientCert(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+
+	e := events.APIGatewayV2HTTPRequest{
+		RawPath: "/pets",
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: "GET",
+			},
+			Authentication: events.APIGatewayV2HTTPRequestContextAuthentication{
+				ClientCert: events.APIGatewayV2HTTPRequestContextAuthenticationClientCert{
+					ClientCertPem: newClientCert(t, notAfter),
+					SubjectDN:     "CN=client.example.com",
+					SerialNumber:  "1",
+				},
+			},
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Len(t, r.TLS.PeerCertificates, 1)
+	assert.Equal(t, "client.example.com", r.TLS.PeerCertificates[0].Subject.CommonName)
+	assert.Equal(t, notAfter, r.TLS.PeerCertificates[0].NotAfter)
+	assert.Len(t, r.TLS.VerifiedChains, 1)
+
+	a, ok := Authentication(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "CN=client.example.com", a.ClientCert.SubjectDN)
+}
+
+func TestNewRequest_noClientCert(t *testing.T) {
+	r, err := NewRequest(context.Background(), events.APIGatewayV2HTTPRequest{})
+	assert.NoError(t, err)
+
+	assert.Nil(t, r.TLS.PeerCertificates)
+
+	_, ok := Authentication(r.Context())
+	assert.False(t, ok)
+}
+
+func TestNewRequest_invalidClientCert(t *testing.T) {
+	e := events.APIGatewayV2HTTPRequest{}
+	e.RequestContext.Authentication.ClientCert.ClientCertPem = "not a certificate"
+
+	_, err := NewRequest(context.Background(), e)
+	assert.EqualError(t, err, "parsing client certificate: no PEM encoded certificate found")
+}
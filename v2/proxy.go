@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// NewProxyGateway creates a gateway for REST APIs and HTTP APIs using the 1.0 payload format.
+func NewProxyGateway(h http.Handler, options ...Option) *ProxyGateway {
+	gw := &ProxyGateway{h: h}
+	for _, o := range options {
+		o(&gw.config)
+	}
+	return gw
+}
+
+// ProxyGateway wrap a http handler to enable use as a lambda.Handler for 1.0 payloads.
+type ProxyGateway struct {
+	h http.Handler
+	config
+}
+
+// Invoke Handler implementation
+func (gw *ProxyGateway) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var evt events.APIGatewayProxyRequest
+
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return []byte{}, err
+	}
+
+	r, err := NewProxyRequest(ctx, evt)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	r, err = gw.prepare(r)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	w := NewProxyResponse()
+	gw.initWriter(&w.ResponseWriter, r)
+	gw.serve(gw.h, &w.ResponseWriter, r)
+
+	resp := w.End()
+
+	return json.Marshal(&resp)
+}
+
+// NewProxyRequest returns a new http.Request from the given 1.0 payload format event,
+// as sent by REST APIs and HTTP APIs configured with payload format version 1.0.
+func NewProxyRequest(ctx context.Context, e events.APIGatewayProxyRequest) (*http.Request, error) {
+	// path
+	u, err := url.Parse(e.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing path")
+	}
+
+	// querystring
+	q := u.Query()
+	for k, v := range e.QueryStringParameters {
+		q.Set(k, v)
+	}
+
+	for k, values := range e.MultiValueQueryStringParameters {
+		q[k] = values
+	}
+	u.RawQuery = q.Encode()
+
+	// new request
+	req, err := http.NewRequest(e.HTTPMethod, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+
+	// body, base64 encoded bodies are decoded while reading
+	if err := setBody(req, e.Body, e.IsBase64Encoded); err != nil {
+		return nil, err
+	}
+
+	// manually set RequestURI because NewRequest is for clients and req.RequestURI is for servers
+	req.RequestURI = u.RequestURI()
+
+	// header fields
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	for k, values := range e.MultiValueHeaders {
+		req.Header[http.CanonicalHeaderKey(k)] = values
+	}
+
+	// content-length
+	if req.Header.Get("Content-Length") == "" && req.ContentLength > 0 {
+		req.Header.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
+	}
+
+	// custom fields
+	req.Header.Set("X-Request-Id", e.RequestContext.RequestID)
+	req.Header.Set("X-Stage", e.RequestContext.Stage)
+
+	// custom context values
+	req = req.WithContext(newProxyContext(ctx, e))
+
+	// path parameters, including greedy {proxy+} parameters
+	for k, v := range e.PathParameters {
+		req.SetPathValue(k, v)
+	}
+
+	// xray support
+	req = setTraceID(req)
+
+	// host
+	req.URL.Host = req.Header.Get("Host")
+	req.Host = req.URL.Host
+
+	// scheme, tls, protocol and remote addr
+	setConnection(req, e.RequestContext.Protocol, e.RequestContext.Identity.SourceIP)
+
+	return req, nil
+}
+
+// ProxyEventFromRequest returns the 1.0 payload format event API Gateway sends for
+// the request, consuming its body.
+//
+// Header fields and query string parameters are set as both single value fields,
+// holding their last values, and multi-value fields, and bodies are base64 encoded
+// when their media type represents binary. The Host and X-Forwarded-* header fields
+// are added, and the request context holds a new request id and the client address
+// of RemoteAddr. The event targets a greedy "/{proxy+}" resource of the $default stage.
+func ProxyEventFromRequest(r *http.Request) (events.APIGatewayProxyRequest, error) {
+	var e events.APIGatewayProxyRequest
+
+	body, encoded, err := eventBody(r, nil)
+	if err != nil {
+		return e, err
+	}
+
+	h, clientIP := forwardedHeader(r)
+	q := r.URL.Query()
+	now := time.Now()
+	domain := h.Get("Host")
+
+	e = events.APIGatewayProxyRequest{
+		Resource:        "/{proxy+}",
+		Path:            r.URL.Path,
+		HTTPMethod:      r.Method,
+		Headers:         make(map[string]string, len(h)),
+		PathParameters:  map[string]string{"proxy": strings.TrimPrefix(r.URL.Path, "/")},
+		Body:            body,
+		IsBase64Encoded: encoded,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID:        newRequestID(),
+			Stage:            "$default",
+			DomainName:       domain,
+			DomainPrefix:     strings.SplitN(domain, ".", 2)[0],
+			HTTPMethod:       r.Method,
+			Path:             r.URL.Path,
+			Protocol:         r.Proto,
+			ResourcePath:     "/{proxy+}",
+			RequestTime:      now.UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			RequestTimeEpoch: now.UnixNano() / int64(time.Millisecond),
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  clientIP,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	e.MultiValueHeaders = h
+	for k, values := range h {
+		e.Headers[k] = values[len(values)-1]
+	}
+
+	if len(q) > 0 {
+		e.QueryStringParameters = make(map[string]string, len(q))
+		e.MultiValueQueryStringParameters = q
+		for k, values := range q {
+			e.QueryStringParameters[k] = values[len(values)-1]
+		}
+	}
+
+	return e, nil
+}
+
+// ProxyResponseFromEvent returns the http.Response API Gateway sends for the 1.0 payload
+// format response event, decoding base64 encoded bodies. The Request field of the response is nil.
+func ProxyResponseFromEvent(e events.APIGatewayProxyResponse) (*http.Response, error) {
+	return newHTTPResponse(e.StatusCode, e.Headers, e.MultiValueHeaders, e.Body, e.IsBase64Encoded)
+}
+
+// ProxyResponseWriter implements the http.ResponseWriter interface
+// in order to support the API Gateway 1.0 payload format "protocol".
+type ProxyResponseWriter struct {
+	ResponseWriter
+}
+
+// NewProxyResponse returns a new response writer to capture http output for 1.0 payloads.
+func NewProxyResponse() *ProxyResponseWriter {
+	return &ProxyResponseWriter{
+		ResponseWriter: ResponseWriter{
+			closeNotifyCh: make(chan bool, 1),
+		},
+	}
+}
+
+// End the request.
+func (w *ProxyResponseWriter) End() events.APIGatewayProxyResponse {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	var out events.APIGatewayProxyResponse
+	out.Body, out.IsBase64Encoded = w.body()
+	out.StatusCode = w.out.StatusCode
+	out.Headers, out.MultiValueHeaders = proxyHeaders(w.header)
+
+	// notify end
+	w.closeNotifyCh <- true
+
+	return out
+}
+
+// proxyHeaders returns the header fields split into single and multi-value fields.
+func proxyHeaders(header http.Header) (map[string]string, map[string][]string) {
+	h := make(map[string]string)
+	mvh := make(map[string][]string)
+
+	for k, v := range header {
+		if len(v) == 1 {
+			h[k] = v[0]
+		} else if len(v) > 1 {
+			mvh[k] = v
+		}
+	}
+
+	return h, mvh
+}
@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestNewProxyRequest(t *testing.T) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/pets",
+		Body:       `{ "name": "Tobi" }`,
+		MultiValueQueryStringParameters: map[string][]string{
+			"fields": {"name", "species"},
+		},
+		MultiValueHeaders: map[string][]string{
+			"x-apex": {"apex1", "apex2"},
+		},
+		Headers: map[string]string{
+			"Host": "example.com",
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID: "1234",
+			Stage:     "prod",
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP: "1.2.3.4",
+			},
+		},
+	}
+
+	r, err := NewProxyRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "POST", r.Method)
+	assert.Equal(t, `/pets?fields=name&fields=species`, r.RequestURI)
+	assert.Equal(t, `example.com`, r.Host)
+	assert.Equal(t, `1.2.3.4:0`, r.RemoteAddr)
+	assert.Equal(t, `prod`, r.Header.Get("X-Stage"))
+	assert.Equal(t, `18`, r.Header.Get("Content-Length"))
+	assert.Equal(t, []string{"apex1", "apex2"}, r.Header["X-Apex"])
+
+	c, ok := ProxyRequestContext(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "1234", c.RequestID)
+}
+
+func TestNewProxyRequest_pathParameters(t *testing.T) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets/luna",
+		Resource:   "/pets/{id}",
+		PathParameters: map[string]string{
+			"id": "luna",
+		},
+	}
+
+	r, err := NewProxyRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `luna`, r.PathValue("id"))
+
+	route, ok := RouteTemplate(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, `/pets/{id}`, route)
+}
+
+func TestProxyResponseWriter_End(t *testing.T) {
+	w := NewProxyResponse()
+	w.Header().Add("X-APEX", "apex1")
+	w.Header().Add("X-APEX", "apex2")
+	w.Write([]byte("hello"))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "hello", e.Body)
+	assert.Equal(t, "text/plain; charset=utf8", e.Headers["Content-Type"])
+	assert.Equal(t, []string{"apex1", "apex2"}, e.MultiValueHeaders["X-Apex"])
+}
+
+func TestProxyEventFromRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "/pets?species=ferret&species=cat", strings.NewReader("hello"))
+	r.Header.Set("Content-Type", "text/plain")
+	r.Header.Add("X-Apex", "apex1")
+	r.Header.Add("X-Apex", "apex2")
+
+	e, err := ProxyEventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/pets", e.Path)
+	assert.Equal(t, "apex2", e.Headers["X-Apex"])
+	assert.Equal(t, []string{"apex1", "apex2"}, e.MultiValueHeaders["X-Apex"])
+	assert.Equal(t, []string{"ferret", "cat"}, e.MultiValueQueryStringParameters["species"])
+	assert.Equal(t, "hello", e.Body)
+	assert.False(t, e.IsBase64Encoded)
+
+	req, err := NewProxyRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/pets?species=ferret&species=cat", req.URL.RequestURI())
+	assert.Equal(t, "hello", string(b))
+}
+
+func TestProxyResponseFromEvent(t *testing.T) {
+	res, err := ProxyResponseFromEvent(events.APIGatewayProxyResponse{
+		StatusCode:        200,
+		Headers:           map[string]string{"Content-Type": "image/png"},
+		MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+		Body:              "iVBORw==",
+		IsBase64Encoded:   true,
+	})
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"a=1", "b=2"}, res.Header["Set-Cookie"])
+	assert.Equal(t, "\x89PNG", string(b))
+}
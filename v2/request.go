@@ -2,12 +2,13 @@ package gateway
 
 import (
 	"context"
-	"encoding/base64"
+	"crypto/rand"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/pkg/errors"
@@ -23,31 +24,23 @@ func NewRequest(ctx context.Context, e events.APIGatewayV2HTTPRequest) (*http.Re
 
 	u.RawQuery = e.RawQueryString
 
-	// base64 encoded body
-	body := e.Body
-	if e.IsBase64Encoded {
-		b, err := base64.StdEncoding.DecodeString(body)
-		if err != nil {
-			return nil, errors.Wrap(err, "decoding base64 body")
-		}
-		body = string(b)
-	}
-
 	// new request
-	req, err := http.NewRequest(e.RequestContext.HTTP.Method, u.String(), strings.NewReader(body))
+	req, err := http.NewRequest(e.RequestContext.HTTP.Method, u.String(), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating request")
 	}
 
+	// body, base64 encoded bodies are decoded while reading
+	if err := setBody(req, e.Body, e.IsBase64Encoded); err != nil {
+		return nil, err
+	}
+
 	// manually set RequestURI because NewRequest is for clients and req.RequestURI is for servers
 	req.RequestURI = u.RequestURI()
 
-	// remote addr
-	req.RemoteAddr = e.RequestContext.HTTP.SourceIP
-
 	// header fields
 	for k, values := range e.Headers {
-		for _, v := range strings.Split(values, ",") {
+		for _, v := range splitHeader(k, values) {
 			req.Header.Add(k, v)
 		}
 	}
@@ -56,8 +49,8 @@ func NewRequest(ctx context.Context, e events.APIGatewayV2HTTPRequest) (*http.Re
 	}
 
 	// content-length
-	if req.Header.Get("Content-Length") == "" && body != "" {
-		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	if req.Header.Get("Content-Length") == "" && req.ContentLength > 0 {
+		req.Header.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
 	}
 
 	// custom fields
@@ -67,14 +60,188 @@ func NewRequest(ctx context.Context, e events.APIGatewayV2HTTPRequest) (*http.Re
 	// custom context values
 	req = req.WithContext(newContext(ctx, e))
 
-	// xray support
-	if traceID := ctx.Value("x-amzn-trace-id"); traceID != nil {
-		req.Header.Set("X-Amzn-Trace-Id", fmt.Sprintf("%v", traceID))
+	// path parameters, including greedy {proxy+} parameters
+	for k, v := range e.PathParameters {
+		req.SetPathValue(k, v)
 	}
 
+	// xray support
+	req = setTraceID(req)
+
 	// host
 	req.URL.Host = req.Header.Get("Host")
 	req.Host = req.URL.Host
 
+	// scheme, tls, protocol and remote addr
+	setConnection(req, e.RequestContext.HTTP.Protocol, e.RequestContext.HTTP.SourceIP)
+
+	// mutual tls client certificate
+	if err := setClientCert(req, e.RequestContext.Authentication.ClientCert.ClientCertPem); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
+
+// singletonRequestHeaders are the fields whose values may contain commas
+// without being lists, so they are never split, see RFC 9110 section 5.3.
+var singletonRequestHeaders = map[string]bool{
+	"Access-Control-Request-Method": true,
+	"Authorization":                 true,
+	"Content-Disposition":           true,
+	"Content-Length":                true,
+	"Content-Md5":                   true,
+	"Content-Type":                  true,
+	"Cookie":                        true,
+	"Date":                          true,
+	"Expires":                       true,
+	"From":                          true,
+	"Host":                          true,
+	"If-Modified-Since":             true,
+	"If-Range":                      true,
+	"If-Unmodified-Since":           true,
+	"Last-Modified":                 true,
+	"Max-Forwards":                  true,
+	"Origin":                        true,
+	"Proxy-Authorization":           true,
+	"Range":                         true,
+	"Referer":                       true,
+	"Sec-Ch-Ua":                     true,
+	"Sec-Websocket-Key":             true,
+	"User-Agent":                    true,
+	"X-Amz-Date":                    true,
+	"X-Amzn-Trace-Id":               true,
+}
+
+// splitHeader splits a header field value which API Gateway joined with commas
+// back into its values. Singleton fields are returned intact, while list
+// elements are split on commas outside of quoted strings and trimmed.
+func splitHeader(name, value string) []string {
+	if singletonRequestHeaders[http.CanonicalHeaderKey(name)] {
+		return []string{value}
+	}
+
+	var values []string
+	var quoted, escaped bool
+	start := 0
+
+	add := func(v string) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			add(value[start:i])
+			start = i + 1
+		}
+	}
+
+	add(value[start:])
+
+	if values == nil {
+		return []string{value}
+	}
+
+	return values
+}
+
+// EventFromRequest returns the HTTP API 2.0 payload format event API Gateway sends
+// for the request, consuming its body.
+//
+// As with API Gateway, header field names are lower-cased and repeated fields joined
+// with commas, cookies are moved from the Cookie header field to the Cookies field,
+// repeated query string parameters are joined with commas, and bodies are base64
+// encoded when their media type represents binary. The Host and X-Forwarded-* header
+// fields are added, and the request context holds a new request id and the client
+// address of RemoteAddr. The event targets the $default route and stage.
+func EventFromRequest(r *http.Request) (events.APIGatewayV2HTTPRequest, error) {
+	return eventFromRequest(r, nil)
+}
+
+// eventFromRequest returns the event for the request, with bodies of the binary media types of m base64 encoded.
+func eventFromRequest(r *http.Request, m *mediaTypes) (events.APIGatewayV2HTTPRequest, error) {
+	var e events.APIGatewayV2HTTPRequest
+
+	body, encoded, err := eventBody(r, m)
+	if err != nil {
+		return e, err
+	}
+
+	h, clientIP := forwardedHeader(r)
+	now := time.Now()
+	domain := h.Get("Host")
+
+	e = events.APIGatewayV2HTTPRequest{
+		Version:         "2.0",
+		RouteKey:        "$default",
+		RawPath:         r.URL.EscapedPath(),
+		RawQueryString:  r.URL.RawQuery,
+		Cookies:         splitCookies(h["Cookie"]),
+		Body:            body,
+		IsBase64Encoded: encoded,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:     "$default",
+			Stage:        "$default",
+			RequestID:    newRequestID(),
+			DomainName:   domain,
+			DomainPrefix: strings.SplitN(domain, ".", 2)[0],
+			Time:         now.UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch:    now.UnixNano() / int64(time.Millisecond),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  clientIP,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	h.Del("Cookie")
+	e.Headers = make(map[string]string, len(h))
+	for k, values := range h {
+		e.Headers[strings.ToLower(k)] = strings.Join(values, ",")
+	}
+
+	if q := r.URL.Query(); len(q) > 0 {
+		e.QueryStringParameters = make(map[string]string, len(q))
+		for k, values := range q {
+			e.QueryStringParameters[k] = strings.Join(values, ",")
+		}
+	}
+
+	return e, nil
+}
+
+// splitCookies returns the cookies of Cookie header field values.
+func splitCookies(values []string) []string {
+	var cookies []string
+
+	for _, v := range values {
+		for _, c := range strings.Split(v, ";") {
+			if c = strings.TrimSpace(c); c != "" {
+				cookies = append(cookies, c)
+			}
+		}
+	}
+
+	return cookies
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
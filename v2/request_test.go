@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"io/ioutil"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -106,7 +107,32 @@ func TestDecodeRequest_remoteAddr(t *testing.T) {
 	r, err := NewRequest(context.Background(), e)
 	assert.NoError(t, err)
 
-	assert.Equal(t, `1.2.3.4`, r.RemoteAddr)
+	assert.Equal(t, `1.2.3.4:0`, r.RemoteAddr)
+}
+
+func TestDecodeRequest_connection(t *testing.T) {
+	e := events.APIGatewayV2HTTPRequest{
+		RawPath: "/pets",
+		Headers: map[string]string{
+			"host":              "example.com",
+			"x-forwarded-proto": "https",
+		},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:   "GET",
+				Path:     "/pets",
+				Protocol: "HTTP/2.0",
+			},
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `https://example.com/pets`, r.URL.String())
+	assert.NotNil(t, r.TLS)
+	assert.Equal(t, `HTTP/2.0`, r.Proto)
+	assert.Equal(t, 2, r.ProtoMajor)
 }
 
 func TestDecodeRequest_header(t *testing.T) {
@@ -173,6 +199,115 @@ func TestDecodeRequest_multiHeader(t *testing.T) {
 	assert.Equal(t, []string{"apex-1", "apex-2"}, r.Header["X-Apex-2"])
 }
 
+func TestDecodeRequest_headerSplitting(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		want   []string
+	}{
+		{
+			name:   "chrome user agent",
+			header: "user-agent",
+			value:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:   []string{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"},
+		},
+		{
+			name:   "aws sdk user agent",
+			header: "User-Agent",
+			value:  "aws-sdk-go-v2/1.24.0 os/linux lang/go#1.21.5 md/GOOS#linux md/GOARCH#amd64 api/s3#1.47.7",
+			want:   []string{"aws-sdk-go-v2/1.24.0 os/linux lang/go#1.21.5 md/GOOS#linux md/GOARCH#amd64 api/s3#1.47.7"},
+		},
+		{
+			name:   "date",
+			header: "date",
+			value:  "Tue, 15 Nov 1994 08:12:31 GMT",
+			want:   []string{"Tue, 15 Nov 1994 08:12:31 GMT"},
+		},
+		{
+			name:   "if-modified-since",
+			header: "if-modified-since",
+			value:  "Sat, 29 Oct 1994 19:43:31 GMT",
+			want:   []string{"Sat, 29 Oct 1994 19:43:31 GMT"},
+		},
+		{
+			name:   "digest authorization",
+			header: "authorization",
+			value:  `Digest username="Mufasa", realm="http-auth@example.org", uri="/dir/index.html", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", response="8ca523f5e9506fed4657c9700eebdbec"`,
+			want:   []string{`Digest username="Mufasa", realm="http-auth@example.org", uri="/dir/index.html", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", response="8ca523f5e9506fed4657c9700eebdbec"`},
+		},
+		{
+			name:   "sigv4 authorization",
+			header: "authorization",
+			value:  "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/execute-api/aws4_request, SignedHeaders=host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+			want:   []string{"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/execute-api/aws4_request, SignedHeaders=host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"},
+		},
+		{
+			name:   "cookie",
+			header: "cookie",
+			value:  `session=abc; prefs="a,b"`,
+			want:   []string{`session=abc; prefs="a,b"`},
+		},
+		{
+			name:   "range",
+			header: "range",
+			value:  "bytes=0-499, 1000-1499",
+			want:   []string{"bytes=0-499, 1000-1499"},
+		},
+		{
+			name:   "browser accept",
+			header: "accept",
+			value:  "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+			want:   []string{"text/html", "application/xhtml+xml", "application/xml;q=0.9", "image/avif", "image/webp", "*/*;q=0.8"},
+		},
+		{
+			name:   "accept with quoted parameter",
+			header: "accept",
+			value:  `application/json; profile="https://example.com/a,b", text/plain`,
+			want:   []string{`application/json; profile="https://example.com/a,b"`, "text/plain"},
+		},
+		{
+			name:   "accept language",
+			header: "accept-language",
+			value:  "en-US,en;q=0.9,fr;q=0.8",
+			want:   []string{"en-US", "en;q=0.9", "fr;q=0.8"},
+		},
+		{
+			name:   "if-none-match",
+			header: "if-none-match",
+			value:  `"xyzzy", W/"r2d2,xxxx"`,
+			want:   []string{`"xyzzy"`, `W/"r2d2,xxxx"`},
+		},
+		{
+			name:   "quoted escape",
+			header: "x-list",
+			value:  `"a\",b", c`,
+			want:   []string{`"a\",b"`, "c"},
+		},
+		{
+			name:   "empty elements",
+			header: "cache-control",
+			value:  "no-cache, , max-age=0,",
+			want:   []string{"no-cache", "max-age=0"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := events.APIGatewayV2HTTPRequest{
+				RawPath: "/pets",
+				Headers: map[string]string{
+					c.header: c.value,
+				},
+			}
+
+			r, err := NewRequest(context.Background(), e)
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, r.Header.Values(c.header))
+		})
+	}
+}
+
 func TestDecodeRequest_cookie(t *testing.T) {
 	e := events.APIGatewayV2HTTPRequest{
 		RawPath: "/pets",
@@ -241,6 +376,41 @@ func TestDecodeRequest_bodyBinary(t *testing.T) {
 	assert.Equal(t, "hello world\n", string(b))
 }
 
+func TestDecodeRequest_pathParameters(t *testing.T) {
+	e := events.APIGatewayV2HTTPRequest{
+		RouteKey: "GET /pets/{id}/{proxy+}",
+		RawPath:  "/pets/luna/photos/beach.jpg",
+		PathParameters: map[string]string{
+			"id":    "luna",
+			"proxy": "photos/beach.jpg",
+		},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: "GET",
+				Path:   "/pets/luna/photos/beach.jpg",
+			},
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `luna`, r.PathValue("id"))
+	assert.Equal(t, `photos/beach.jpg`, r.PathValue("proxy"))
+
+	route, ok := RouteTemplate(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, `GET /pets/{id}/{proxy+}`, route)
+}
+
+func TestDecodeRequest_noRoute(t *testing.T) {
+	r, err := NewRequest(context.Background(), events.APIGatewayV2HTTPRequest{})
+	assert.NoError(t, err)
+
+	_, ok := RouteTemplate(r.Context())
+	assert.False(t, ok)
+}
+
 func TestDecodeRequest_context(t *testing.T) {
 	e := events.APIGatewayV2HTTPRequest{}
 	ctx := context.WithValue(context.Background(), "key", "value")
@@ -249,3 +419,58 @@ func TestDecodeRequest_context(t *testing.T) {
 	v := r.Context().Value("key")
 	assert.Equal(t, "value", v)
 }
+
+func TestEventFromRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "https://example.com/pets%2Fall?species=ferret&species=cat", strings.NewReader("\x89PNG"))
+	r.Header.Set("Content-Type", "image/png")
+	r.Header.Add("X-Apex", "apex1")
+	r.Header.Add("X-Apex", "apex2")
+	r.Header.Add("Cookie", "a=1; b=2")
+	r.Header.Add("Cookie", "c=3")
+
+	e, err := EventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "2.0", e.Version)
+	assert.Equal(t, "$default", e.RouteKey)
+	assert.Equal(t, "POST", e.RequestContext.HTTP.Method)
+	assert.Equal(t, "/pets%2Fall", e.RawPath)
+	assert.Equal(t, "species=ferret&species=cat", e.RawQueryString)
+	assert.Equal(t, "ferret,cat", e.QueryStringParameters["species"])
+	assert.Equal(t, "apex1,apex2", e.Headers["x-apex"])
+	assert.Equal(t, "example.com", e.Headers["host"])
+	assert.Equal(t, "https", e.Headers["x-forwarded-proto"])
+	assert.Equal(t, "", e.Headers["cookie"])
+	assert.Equal(t, []string{"a=1", "b=2", "c=3"}, e.Cookies)
+	assert.Equal(t, "iVBORw==", e.Body)
+	assert.True(t, e.IsBase64Encoded)
+	assert.Equal(t, "192.0.2.1", e.RequestContext.HTTP.SourceIP)
+	assert.Equal(t, "example", e.RequestContext.DomainPrefix)
+	assert.NotEmpty(t, e.RequestContext.RequestID)
+
+	req, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/pets%2Fall?species=ferret&species=cat", req.URL.String())
+	assert.Equal(t, []string{"apex1", "apex2"}, req.Header["X-Apex"])
+	assert.Len(t, req.Cookies(), 3)
+	assert.Equal(t, "192.0.2.1:0", req.RemoteAddr)
+	assert.Equal(t, "\x89PNG", string(b))
+}
+
+func TestEventFromRequest_text(t *testing.T) {
+	r := httptest.NewRequest("POST", "/pets", strings.NewReader(`{"name":"Tobi"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	e, err := EventFromRequest(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `{"name":"Tobi"}`, e.Body)
+	assert.False(t, e.IsBase64Encoded)
+	assert.Equal(t, "http", e.Headers["x-forwarded-proto"])
+	assert.Nil(t, e.Cookies)
+	assert.Nil(t, e.QueryStringParameters)
+}
@@ -2,8 +2,10 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
-	"mime"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -18,6 +20,12 @@ type ResponseWriter struct {
 	header        http.Header
 	wroteHeader   bool
 	closeNotifyCh chan bool
+	mediaTypes    *mediaTypes
+
+	compression    *compression
+	acceptEncoding string
+	payloadLimit   *PayloadLimit
+	ctx            context.Context
 }
 
 // NewResponse returns a new response writer to capture http output.
@@ -56,23 +64,17 @@ func (w *ResponseWriter) WriteHeader(status int) {
 	}
 
 	w.out.StatusCode = status
-
-	h := make(map[string]string)
-	mvh := make(map[string][]string)
-
-	for k, v := range w.Header() {
-		if len(v) == 1 {
-			h[k] = v[0]
-		} else if len(v) > 1 {
-			mvh[k] = v
-		}
-	}
-
-	w.out.Headers = h
-	w.out.MultiValueHeaders = mvh
+	w.writeHeaders()
 	w.wroteHeader = true
 }
 
+// writeHeaders sets the response header fields, HTTP APIs ignore
+// multi-value headers so fields with multiple values are folded.
+func (w *ResponseWriter) writeHeaders() {
+	w.out.Headers = foldHeaders(w.Header())
+	w.out.MultiValueHeaders = make(map[string][]string)
+}
+
 // CloseNotify notify when the response is closed
 func (w *ResponseWriter) CloseNotify() <-chan bool {
 	return w.closeNotifyCh
@@ -80,13 +82,7 @@ func (w *ResponseWriter) CloseNotify() <-chan bool {
 
 // End the request.
 func (w *ResponseWriter) End() events.APIGatewayV2HTTPResponse {
-	w.out.IsBase64Encoded = isBinary(w.header)
-
-	if w.out.IsBase64Encoded {
-		w.out.Body = base64.StdEncoding.EncodeToString(w.buf.Bytes())
-	} else {
-		w.out.Body = w.buf.String()
-	}
+	w.out.Body, w.out.IsBase64Encoded = w.body()
 
 	// see https://aws.amazon.com/blogs/compute/simply-serverless-using-aws-lambda-to-expose-custom-cookies-with-api-gateway/
 	w.out.Cookies = w.header["Set-Cookie"]
@@ -98,33 +94,129 @@ func (w *ResponseWriter) End() events.APIGatewayV2HTTPResponse {
 	return w.out
 }
 
+// body returns the buffered body, compressed when enabled and
+// base64 encoded when it represents binary.
+func (w *ResponseWriter) body() (string, bool) {
+	if w.compression != nil && w.compression.compress(w.header, w.out.StatusCode, &w.buf, w.acceptEncoding) {
+		w.writeHeaders()
+	}
+
+	if isBinary(w.header, w.mediaTypes) {
+		return w.limit(base64.StdEncoding.EncodeToString(w.buf.Bytes()), true)
+	}
+
+	return w.limit(w.buf.String(), false)
+}
+
+// singletonHeaders are the fields defined as a single value, which can not
+// be folded into a comma-separated list, see RFC 9110 section 5.3.
+var singletonHeaders = map[string]bool{
+	"Access-Control-Allow-Credentials": true,
+	"Access-Control-Allow-Origin":      true,
+	"Access-Control-Max-Age":           true,
+	"Age":                              true,
+	"Content-Disposition":              true,
+	"Content-Length":                   true,
+	"Content-Location":                 true,
+	"Content-Range":                    true,
+	"Content-Type":                     true,
+	"Date":                             true,
+	"Etag":                             true,
+	"Expires":                          true,
+	"Last-Modified":                    true,
+	"Location":                         true,
+	"Retry-After":                      true,
+	"Server":                           true,
+	"Strict-Transport-Security":        true,
+
+	// Challenges contain commas in their parameters and are often parsed as
+	// a single value by clients, so only the first challenge is sent and any
+	// others are dropped.
+	"Proxy-Authenticate": true,
+	"Www-Authenticate":   true,
+}
+
+// foldHeaders returns the header fields folded into single values, omitting
+// Set-Cookie which is sent separately as cookies. List-based fields are joined
+// with commas as permitted by RFC 9110 section 5.3, singleton fields and
+// authentication challenges keep their first value as joining them would
+// change their meaning.
+func foldHeaders(h http.Header) map[string]string {
+	m := make(map[string]string)
+
+	for k, v := range h {
+		switch {
+		case len(v) == 0 || k == "Set-Cookie":
+			continue
+		case singletonHeaders[k]:
+			m[k] = v[0]
+		default:
+			m[k] = strings.Join(v, ", ")
+		}
+	}
+
+	return m
+}
+
 // isBinary returns true if the response reprensents binary.
-func isBinary(h http.Header) bool {
+func isBinary(h http.Header, m *mediaTypes) bool {
 	switch {
-	case !isTextMime(h.Get("Content-Type")):
+	case m.isBinary(h.Get("Content-Type")):
 		return true
-	case h.Get("Content-Encoding") == "gzip":
+	case h.Get("Content-Encoding") != "" && h.Get("Content-Encoding") != "identity":
 		return true
 	default:
 		return false
 	}
 }
 
-// isTextMime returns true if the content type represents textual data.
+// isTextMime returns true if the content type represents textual data by default.
 func isTextMime(kind string) bool {
-	mt, _, err := mime.ParseMediaType(kind)
+	var m *mediaTypes
+	return !m.isBinary(kind)
+}
+
+// ResponseFromEvent returns the http.Response API Gateway sends for the HTTP API 2.0
+// response event, decoding base64 encoded bodies and setting a Set-Cookie header field
+// for each cookie. The Request field of the response is nil.
+func ResponseFromEvent(e events.APIGatewayV2HTTPResponse) (*http.Response, error) {
+	res, err := newHTTPResponse(e.StatusCode, e.Headers, e.MultiValueHeaders, e.Body, e.IsBase64Encoded)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	if strings.HasPrefix(mt, "text/") {
-		return true
+	for _, c := range e.Cookies {
+		res.Header.Add("Set-Cookie", c)
 	}
 
-	switch mt {
-	case "image/svg+xml", "application/json", "application/xml", "application/javascript":
-		return true
-	default:
-		return false
+	return res, nil
+}
+
+// newHTTPResponse returns the http.Response for the fields of a response event,
+// multi-value header fields take precedence over single value fields.
+func newHTTPResponse(status int, h map[string]string, mvh map[string][]string, body string, encoded bool) (*http.Response, error) {
+	b, err := decodeBody(body, encoded)
+	if err != nil {
+		return nil, err
 	}
+
+	header := make(http.Header)
+	for k, v := range h {
+		header.Set(k, v)
+	}
+
+	for k, values := range mvh {
+		header[http.CanonicalHeaderKey(k)] = values
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+	}, nil
 }
@@ -2,8 +2,10 @@ package gateway
 
 import (
 	"bytes"
+	"io/ioutil"
 	"testing"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/tj/assert"
 )
 
@@ -105,3 +107,92 @@ func TestResponseWriter_WriteHeader(t *testing.T) {
 	assert.Equal(t, "Not Found\n", e.Body)
 	assert.Equal(t, "text/plain; charset=utf8", e.Headers["Content-Type"])
 }
+
+func TestResponseWriter_foldHeaders(t *testing.T) {
+	w := NewResponse()
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Link", `</style.css>; rel=preload; as=style`)
+	w.Header().Add("Link", `</app.js>; rel=preload; as=script`)
+	w.Header().Add("Cache-Control", "no-cache")
+	w.Header().Add("Cache-Control", "no-store")
+	w.Header().Add("WWW-Authenticate", `Basic realm="simple"`)
+	w.Header().Add("WWW-Authenticate", `Bearer realm="example", error="invalid_token"`)
+	w.Header().Add("Set-Cookie", "a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Set("X-Single", "one")
+	w.Write([]byte("hello"))
+
+	e := w.End()
+	assert.Equal(t, map[string]string{
+		"Content-Type":     "text/plain; charset=utf8",
+		"Vary":             "Accept, Origin",
+		"Link":             `</style.css>; rel=preload; as=style, </app.js>; rel=preload; as=script`,
+		"Cache-Control":    "no-cache, no-store",
+		"Www-Authenticate": `Basic realm="simple"`,
+		"X-Single":         "one",
+	}, e.Headers)
+	assert.Equal(t, []string{"a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT", "b=2"}, e.Cookies)
+	assert.Empty(t, e.MultiValueHeaders)
+}
+
+func TestResponseWriter_foldHeaders_singleton(t *testing.T) {
+	unsafe := map[string][]string{
+		"Access-Control-Allow-Credentials": {"true", "false"},
+		"Access-Control-Allow-Origin":      {"https://a.example.com", "https://b.example.com"},
+		"Access-Control-Max-Age":           {"600", "60"},
+		"Age":                              {"10", "20"},
+		"Content-Disposition":              {`attachment; filename="a, b.txt"`, "inline"},
+		"Content-Length":                   {"5", "6"},
+		"Content-Location":                 {"/a", "/b"},
+		"Content-Range":                    {"bytes 0-4/10", "bytes 5-9/10"},
+		"Content-Type":                     {"text/plain", "text/html"},
+		"Date":                             {"Wed, 21 Oct 2015 07:28:00 GMT", "Thu, 22 Oct 2015 07:28:00 GMT"},
+		"Etag":                             {`"abc"`, `"def"`},
+		"Expires":                          {"Wed, 21 Oct 2015 07:28:00 GMT", "0"},
+		"Last-Modified":                    {"Wed, 21 Oct 2015 07:28:00 GMT", "Thu, 22 Oct 2015 07:28:00 GMT"},
+		"Location":                         {"/a,b", "/c"},
+		"Retry-After":                      {"Wed, 21 Oct 2015 07:28:00 GMT", "120"},
+		"Server":                           {"gateway", "lambda"},
+		"Strict-Transport-Security":        {"max-age=31536000", "max-age=0"},
+		"Www-Authenticate":                 {`Basic realm="a, b"`, `Bearer realm="example", error="invalid_token"`},
+		"Proxy-Authenticate":               {`Basic realm="proxy"`, `Digest realm="proxy", qop="auth, auth-int"`},
+	}
+
+	for name, values := range unsafe {
+		t.Run(name, func(t *testing.T) {
+			w := NewResponse()
+			w.Header()[name] = values
+			w.Write([]byte("hello"))
+
+			e := w.End()
+			assert.Equal(t, values[0], e.Headers[name])
+		})
+	}
+}
+
+func TestResponseFromEvent(t *testing.T) {
+	res, err := ResponseFromEvent(events.APIGatewayV2HTTPResponse{
+		StatusCode:      201,
+		Headers:         map[string]string{"content-type": "text/plain", "x-apex": "apex1, apex2"},
+		Cookies:         []string{"a=1", "b=2"},
+		Body:            "Created\n",
+		IsBase64Encoded: false,
+	})
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, "201 Created", res.Status)
+	assert.Equal(t, "text/plain", res.Header.Get("Content-Type"))
+	assert.Equal(t, "apex1, apex2", res.Header.Get("X-Apex"))
+	assert.Equal(t, []string{"a=1", "b=2"}, res.Header["Set-Cookie"])
+	assert.Equal(t, "Created\n", string(b))
+}
+
+func TestResponseFromEvent_invalidBase64(t *testing.T) {
+	_, err := ResponseFromEvent(events.APIGatewayV2HTTPResponse{StatusCode: 200, Body: "a===", IsBase64Encoded: true})
+	assert.EqualError(t, err, "decoding base64 body: illegal base64 data at input byte 1")
+}
@@ -0,0 +1,144 @@
+package gateway_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apex/gateway/v2"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+type ctxKey struct{}
+
+// invoke invokes the server with a POST request with the body, returning the response.
+func invoke(t *testing.T, s *gateway.Server, header map[string]string, body string) events.APIGatewayV2HTTPResponse {
+	e, err := json.Marshal(events.APIGatewayV2HTTPRequest{
+		Version: "2.0",
+		RawPath: "/pets",
+		Headers: header,
+		Body:    body,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: "POST",
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	payload, err := s.Invoke(context.Background(), e)
+	assert.NoError(t, err)
+
+	var resp events.APIGatewayV2HTTPResponse
+	assert.NoError(t, json.Unmarshal(payload, &resp))
+	return resp
+}
+
+func TestServer_Invoke(t *testing.T) {
+	s := &gateway.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Context().Value(ctxKey{}).(string)))
+		}),
+		BaseContext: func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, ctxKey{}, "base")
+		},
+		Options: []gateway.Option{gateway.WithBinaryMediaTypes("*/*")},
+	}
+
+	resp := invoke(t, s, nil, "")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "YmFzZQ==", resp.Body)
+}
+
+func TestServer_Invoke_maxHeaderBytes(t *testing.T) {
+	s := &gateway.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}),
+		MaxHeaderBytes: 256,
+	}
+
+	resp := invoke(t, s, map[string]string{"X-Small": "value"}, "")
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp = invoke(t, s, map[string]string{"X-Large": strings.Repeat("a", 256)}, "")
+	assert.Equal(t, 431, resp.StatusCode)
+	assert.Equal(t, "Request Header Fields Too Large\n", resp.Body)
+}
+
+func TestServer_Invoke_readTimeout(t *testing.T) {
+	s := &gateway.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(20 * time.Millisecond)
+			_, err := ioutil.ReadAll(r.Body)
+			w.Write([]byte(err.Error()))
+		}),
+		ReadTimeout: 10 * time.Millisecond,
+	}
+
+	resp := invoke(t, s, nil, "hello")
+	assert.Equal(t, os.ErrDeadlineExceeded.Error(), resp.Body)
+}
+
+func TestServer_Invoke_writeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	s := &gateway.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}),
+		WriteTimeout: 50 * time.Millisecond,
+	}
+
+	resp := invoke(t, s, nil, "")
+	assert.Equal(t, 504, resp.StatusCode)
+}
+
+func TestServer_Invoke_panic(t *testing.T) {
+	var buf bytes.Buffer
+	s := &gateway.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+		ErrorLog: log.New(&buf, "", 0),
+	}
+
+	_, err := s.Invoke(context.Background(), []byte(`{"version": "2.0", "rawPath": "/", "requestContext": {"http": {"method": "GET"}}}`))
+	assert.EqualError(t, err, "handler panic: boom")
+	assert.Contains(t, buf.String(), "gateway: panic serving invocation: boom")
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	s := &gateway.Server{
+		Handler: http.NotFoundHandler(),
+	}
+
+	var called bool
+	s.RegisterOnShutdown(func() {
+		called = true
+	})
+
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.True(t, called)
+
+	_, err := s.Invoke(context.Background(), []byte(`{"version": "2.0", "rawPath": "/", "requestContext": {"http": {"method": "GET"}}}`))
+	assert.Equal(t, http.ErrServerClosed, err)
+
+	s = &gateway.Server{}
+	s.RegisterOnShutdown(func() {
+		time.Sleep(time.Second)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, s.Shutdown(ctx))
+}
@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID sets the client's last event id, sent back in Last-Event-ID when reconnecting.
+	ID string
+
+	// Event is the event type, clients default to "message" when empty.
+	Event string
+
+	// Data is the event payload, multiple lines are sent as multiple data fields.
+	Data string
+
+	// Retry sets the client's reconnection delay when non-zero.
+	Retry time.Duration
+}
+
+// EventStreamConfig configures an EventStream.
+type EventStreamConfig struct {
+	// KeepAlive is the interval between keep-alive comments, defaults to 15 seconds.
+	KeepAlive time.Duration
+
+	// DeadlineMargin is how long before the invocation deadline the stream is closed, defaults to 1 second.
+	DeadlineMargin time.Duration
+}
+
+// EventStream writes Server-Sent Events to a streaming response.
+//
+// The stream's context is cancelled DeadlineMargin before the Lambda invocation
+// deadline, handlers should return when it is done so the stream ends cleanly
+// before Lambda terminates the invocation, clients then reconnect with Last-Event-ID.
+// Handlers must call Close before returning.
+type EventStream struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	f      http.Flusher
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewEventStream writes the event stream headers and returns a new stream, an error
+// is returned when w does not support flushing, such as when using the buffered Gateway.
+func NewEventStream(w http.ResponseWriter, r *http.Request, c EventStreamConfig) (*EventStream, error) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("response writer does not support streaming")
+	}
+
+	if c.KeepAlive == 0 {
+		c.KeepAlive = 15 * time.Second
+	}
+
+	if c.DeadlineMargin == 0 {
+		c.DeadlineMargin = time.Second
+	}
+
+	s := &EventStream{
+		w:    w,
+		f:    f,
+		done: make(chan struct{}),
+	}
+
+	if deadline, ok := r.Context().Deadline(); ok {
+		s.ctx, s.cancel = context.WithDeadline(r.Context(), deadline.Add(-c.DeadlineMargin))
+	} else {
+		s.ctx, s.cancel = context.WithCancel(r.Context())
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+
+	go s.keepAlive(c.KeepAlive)
+
+	return s, nil
+}
+
+// Context returns the stream's context, done when the stream is closed or the deadline approaches.
+func (s *EventStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes and flushes the event.
+func (s *EventStream) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n") || strings.ContainsAny(e.Event, "\r\n") {
+		return errors.New("event id and type must not contain newlines")
+	}
+
+	var b strings.Builder
+
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %s\n", strconv.FormatInt(int64(e.Retry/time.Millisecond), 10))
+	}
+
+	for _, line := range strings.Split(newlines.Replace(e.Data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+// Comment writes and flushes a comment, ignored by clients.
+func (s *EventStream) Comment(text string) error {
+	return s.write(": " + strings.Replace(newlines.Replace(text), "\n", " ", -1) + "\n\n")
+}
+
+// newlines normalises the CRLF, CR and LF line endings of event streams to LF,
+// so a lone CR cannot start a new field.
+var newlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Close stops the keep-alive comments and cancels the stream's context.
+func (s *EventStream) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// write writes and flushes s unless the stream is closed.
+func (s *EventStream) write(str string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.w.Write([]byte(str)); err != nil {
+		return err
+	}
+
+	s.f.Flush()
+	return nil
+}
+
+// keepAlive writes a comment every interval until the stream is closed.
+func (s *EventStream) keepAlive(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	defer close(s.done)
+
+	for {
+		select {
+		case <-t.C:
+			s.Comment("keep-alive")
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+func TestNewEventStream_buffered(t *testing.T) {
+	r := httptest.NewRequest("GET", "/events", nil)
+
+	_, err := NewEventStream(NewResponse(), r, EventStreamConfig{})
+	assert.EqualError(t, err, "response writer does not support streaming")
+}
+
+func TestEventStream_Send(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStreamingResponse(&buf)
+	r := httptest.NewRequest("GET", "/events", nil)
+
+	s, err := NewEventStream(w, r, EventStreamConfig{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Send(Event{ID: "1", Event: "update", Data: "line one\nline two", Retry: 2 * time.Second}))
+	assert.NoError(t, s.Send(Event{Data: "hello"}))
+	assert.NoError(t, s.Send(Event{Data: "a\rb\r\nc\rid: 2"}))
+	assert.EqualError(t, s.Send(Event{ID: "1\n2"}), "event id and type must not contain newlines")
+	assert.EqualError(t, s.Send(Event{ID: "1\r2"}), "event id and type must not contain newlines")
+	assert.EqualError(t, s.Send(Event{Event: "a\revent: b"}), "event id and type must not contain newlines")
+	assert.NoError(t, s.Comment("ping"))
+	assert.NoError(t, s.Comment("a\rdata: b\r\nc"))
+	s.Close()
+	assert.Equal(t, context.Canceled, s.Send(Event{Data: "late"}))
+	assert.NoError(t, w.End())
+
+	out := buf.String()
+	prelude := out[:strings.Index(out, "\x00")]
+	assert.Equal(t, `{"statusCode":200,"headers":{"Cache-Control":"no-cache","Content-Type":"text/event-stream"}}`, prelude)
+
+	body := strings.TrimLeft(out[len(prelude):], "\x00")
+	assert.Equal(t, "id: 1\nevent: update\nretry: 2000\ndata: line one\ndata: line two\n\ndata: hello\n\ndata: a\ndata: b\ndata: c\ndata: id: 2\n\n: ping\n\n: a data: b c\n\n", body)
+}
+
+func TestEventStream_deadline(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStreamingResponse(&buf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+
+	s, err := NewEventStream(w, r, EventStreamConfig{
+		KeepAlive:      50 * time.Millisecond,
+		DeadlineMargin: 100 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	<-s.Context().Done()
+	s.Close()
+	assert.True(t, time.Since(start) < 200*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded, s.Context().Err())
+	assert.NoError(t, w.End())
+
+	assert.Contains(t, buf.String(), ": keep-alive\n\n")
+}
+
+func TestStreamingGateway_eventStream(t *testing.T) {
+	var got bytes.Buffer
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := NewEventStream(w, r, EventStreamConfig{})
+		assert.NoError(t, err)
+		defer s.Close()
+
+		for i := 0; i < 3; i++ {
+			s.Send(Event{Data: strings.Repeat("x", i+1)})
+		}
+	})
+
+	rd, err := NewStreamingGateway(h).Invoke(context.Background(), events.LambdaFunctionURLRequest{RawPath: "/events"})
+	assert.NoError(t, err)
+
+	_, err = got.ReadFrom(rd)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(got.String(), "data: x\n\ndata: xx\n\ndata: xxx\n\n"))
+}
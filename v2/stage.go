@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"context"
+	"encoding"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// durationType is the type of time.Duration fields.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// WithStageConfig binds the stage variables of each request into a new value of the
+// struct type v points to, available to handlers with StageConfig. It panics if v is
+// not a pointer to a struct.
+func WithStageConfig(v interface{}) Option {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("gateway: WithStageConfig requires a pointer to a struct")
+	}
+
+	return func(c *config) {
+		c.stageConfig = t.Elem()
+	}
+}
+
+// StageConfig returns the stage config bound for the request, a pointer to a value of the
+// type passed to WithStageConfig, or nil when the option is not used.
+func StageConfig(ctx context.Context) interface{} {
+	return ctx.Value(stageConfigContextKey)
+}
+
+// BindStageVariables binds stage variables into the struct pointed to by v.
+//
+// Fields are bound from the variable named by their `stage` tag, falling back to their
+// `default` tag when the variable is not set. Supported field types are strings, bools,
+// numbers, time.Duration, comma separated string slices and encoding.TextUnmarshaler.
+func BindStageVariables(vars map[string]string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("binding requires a pointer to a struct")
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+
+		name := f.Tag.Get("stage")
+		if name == "" || name == "-" || f.PkgPath != "" {
+			continue
+		}
+
+		s, ok := vars[name]
+		if !ok {
+			s, ok = f.Tag.Lookup("default")
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := setField(rv.Field(i), s); err != nil {
+			return errors.Wrapf(err, "binding stage variable %q", name)
+		}
+	}
+
+	return nil
+}
+
+// setField sets the field to the parsed value of s.
+func setField(f reflect.Value, s string) error {
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		if f.Type().Elem().Kind() != reflect.String {
+			return errors.Errorf("unsupported type %s", f.Type())
+		}
+		values := reflect.MakeSlice(f.Type(), 0, 0)
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = reflect.Append(values, reflect.ValueOf(v).Convert(f.Type().Elem()))
+			}
+		}
+		f.Set(values)
+	default:
+		return errors.Errorf("unsupported type %s", f.Type())
+	}
+
+	return nil
+}
+
+// bindStage returns the request with its stage config, when configured.
+func (c *config) bindStage(r *http.Request) (*http.Request, error) {
+	if c.stageConfig == nil {
+		return r, nil
+	}
+
+	vars, _ := StageVariables(r.Context())
+	v := reflect.New(c.stageConfig).Interface()
+
+	if err := BindStageVariables(vars, v); err != nil {
+		return nil, err
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), stageConfigContextKey, v)), nil
+}
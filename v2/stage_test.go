@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/tj/assert"
+)
+
+type stageConfig struct {
+	Backend  string        `stage:"backend"`
+	Beta     bool          `stage:"beta"`
+	Retries  int           `stage:"retries" default:"3"`
+	Ratio    float64       `stage:"ratio"`
+	Timeout  time.Duration `stage:"timeout" default:"5s"`
+	Origins  []string      `stage:"origins"`
+	Address  net.IP        `stage:"address"`
+	Ignored  string
+	internal string `stage:"internal"`
+}
+
+func TestBindStageVariables(t *testing.T) {
+	var c stageConfig
+	err := BindStageVariables(map[string]string{
+		"backend":  "https://api.example.com",
+		"beta":     "true",
+		"ratio":    "0.25",
+		"timeout":  "1m",
+		"origins":  "https://a.example.com, https://b.example.com",
+		"address":  "10.0.0.1",
+		"Ignored":  "ignored",
+		"internal": "internal",
+	}, &c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://api.example.com", c.Backend)
+	assert.True(t, c.Beta)
+	assert.Equal(t, 3, c.Retries)
+	assert.Equal(t, 0.25, c.Ratio)
+	assert.Equal(t, time.Minute, c.Timeout)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, c.Origins)
+	assert.Equal(t, "10.0.0.1", c.Address.String())
+	assert.Equal(t, "", c.Ignored)
+	assert.Equal(t, "", c.internal)
+}
+
+func TestBindStageVariables_invalid(t *testing.T) {
+	var c stageConfig
+	err := BindStageVariables(map[string]string{"retries": "many"}, &c)
+	assert.EqualError(t, err, `binding stage variable "retries": strconv.ParseInt: parsing "many": invalid syntax`)
+
+	err = BindStageVariables(nil, c)
+	assert.EqualError(t, err, `binding requires a pointer to a struct`)
+}
+
+func TestWithStageConfig(t *testing.T) {
+	assert.Panics(t, func() {
+		WithStageConfig(stageConfig{})
+	})
+
+	var c config
+	WithStageConfig(&stageConfig{})(&c)
+
+	ctx := withStageVariables(context.Background(), map[string]string{"backend": "https://beta.example.com"})
+	r, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	r, err = c.bindStage(r.WithContext(ctx))
+	assert.NoError(t, err)
+
+	v, ok := StageConfig(r.Context()).(*stageConfig)
+	assert.True(t, ok)
+	assert.Equal(t, "https://beta.example.com", v.Backend)
+	assert.Equal(t, 3, v.Retries)
+}
+
+func TestStageVariables(t *testing.T) {
+	e := events.APIGatewayV2HTTPRequest{
+		RawPath: "/pets",
+		StageVariables: map[string]string{
+			"backend": "https://api.example.com",
+		},
+	}
+
+	r, err := NewRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	vars, ok := StageVariables(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.example.com", vars["backend"])
+}
+
+func TestStageVariables_proxy(t *testing.T) {
+	e := events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/pets",
+		StageVariables: map[string]string{
+			"backend": "https://api.example.com",
+		},
+	}
+
+	r, err := NewProxyRequest(context.Background(), e)
+	assert.NoError(t, err)
+
+	vars, ok := StageVariables(r.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.example.com", vars["backend"])
+}
@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// streamingContentType is the content type Lambda expects for streamed HTTP integration responses.
+const streamingContentType = "application/vnd.awslambda.http-integration-response"
+
+// streamingDelimiter separates the JSON prelude from the body in a streamed response.
+var streamingDelimiter = make([]byte, 8)
+
+// NewStreamingGateway creates a gateway streaming responses to Lambda Function URLs using the provided http.Handler.
+func NewStreamingGateway(h http.Handler, options ...Option) *StreamingGateway {
+	gw := &StreamingGateway{h: h}
+	for _, o := range options {
+		o(&gw.config)
+	}
+	return gw
+}
+
+// StreamingGateway wrap a http handler to stream responses to Lambda Function URLs
+// configured with the RESPONSE_STREAM invoke mode, calls to http.Flusher.Flush
+// send the buffered output to the client immediately.
+//
+// Use with lambda.Start(gw.Invoke), which requires the `provided` runtimes
+// or compiling with `-tags lambda.norpc`.
+type StreamingGateway struct {
+	h http.Handler
+	config
+}
+
+// Invoke Handler implementation, the returned reader is the response stream.
+func (gw *StreamingGateway) Invoke(ctx context.Context, e events.LambdaFunctionURLRequest) (io.Reader, error) {
+	r, err := NewFunctionURLRequest(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err = gw.prepare(r)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	w := NewStreamingResponse(pw)
+
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				pw.CloseWithError(fmt.Errorf("handler panic: %v", v))
+			}
+		}()
+
+		gw.h.ServeHTTP(w, r)
+		pw.CloseWithError(w.End())
+	}()
+
+	return &streamingResponse{pr}, nil
+}
+
+// streamingResponse is the response stream handed to the Lambda runtime.
+type streamingResponse struct {
+	*io.PipeReader
+}
+
+// ContentType implementation.
+func (r *streamingResponse) ContentType() string {
+	return streamingContentType
+}
+
+// StreamingResponseWriter implements the http.ResponseWriter and http.Flusher
+// interfaces in order to support the Lambda response streaming "protocol".
+type StreamingResponseWriter struct {
+	w             *bufio.Writer
+	header        http.Header
+	wroteHeader   bool
+	err           error
+	closeNotifyCh chan bool
+}
+
+// NewStreamingResponse returns a new response writer streaming http output to w.
+func NewStreamingResponse(w io.Writer) *StreamingResponseWriter {
+	return &StreamingResponseWriter{
+		w:             bufio.NewWriter(w),
+		closeNotifyCh: make(chan bool, 1),
+	}
+}
+
+// Header implementation.
+func (w *StreamingResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+
+	return w.header
+}
+
+// Write implementation.
+func (w *StreamingResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(b)
+	w.err = err
+	return n, err
+}
+
+// WriteHeader implementation, the prelude is buffered until the first flush.
+func (w *StreamingResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf8")
+	}
+
+	w.wroteHeader = true
+
+	prelude, err := json.Marshal(struct {
+		StatusCode int               `json:"statusCode"`
+		Headers    map[string]string `json:"headers,omitempty"`
+		Cookies    []string          `json:"cookies,omitempty"`
+	}{
+		StatusCode: status,
+		Headers:    foldHeaders(w.header),
+		Cookies:    w.header["Set-Cookie"],
+	})
+	if err != nil {
+		w.err = err
+		return
+	}
+
+	w.w.Write(prelude)
+	_, w.err = w.w.Write(streamingDelimiter)
+}
+
+// Flush implementation, sending buffered output to the client.
+func (w *StreamingResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+}
+
+// CloseNotify notify when the response is closed
+func (w *StreamingResponseWriter) CloseNotify() <-chan bool {
+	return w.closeNotifyCh
+}
+
+// End the request, flushing any buffered output and returning the first write error.
+func (w *StreamingResponseWriter) End() error {
+	w.Flush()
+
+	// notify end
+	w.closeNotifyCh <- true
+
+	return w.err
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/tj/assert"
+)
+
+// writeRecorder records each write made to it.
+type writeRecorder struct {
+	writes []string
+}
+
+func (w *writeRecorder) Write(b []byte) (int, error) {
+	w.writes = append(w.writes, string(b))
+	return len(b), nil
+}
+
+func TestStreamingResponseWriter(t *testing.T) {
+	rec := &writeRecorder{}
+	w := NewStreamingResponse(rec)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Add("Set-Cookie", "a=1")
+	w.WriteHeader(201)
+	assert.Empty(t, rec.writes)
+
+	w.Write([]byte("hello "))
+	w.Flush()
+	assert.Equal(t, []string{`{"statusCode":201,"headers":{"Content-Type":"text/event-stream"},"cookies":["a=1"]}` + "\x00\x00\x00\x00\x00\x00\x00\x00hello "}, rec.writes)
+
+	w.Write([]byte("world"))
+	assert.NoError(t, w.End())
+	assert.Equal(t, "world", rec.writes[1])
+	assert.True(t, <-w.CloseNotify())
+}
+
+func TestStreamingResponseWriter_empty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStreamingResponse(&buf)
+	assert.NoError(t, w.End())
+	assert.Equal(t, `{"statusCode":200,"headers":{"Content-Type":"text/plain; charset=utf8"}}`+"\x00\x00\x00\x00\x00\x00\x00\x00", buf.String())
+}
+
+func TestStreamingResponseWriter_writeError(t *testing.T) {
+	pr, pw := io.Pipe()
+	pr.Close()
+
+	w := NewStreamingResponse(pw)
+	w.Write([]byte("hello"))
+	assert.Equal(t, io.ErrClosedPipe, w.End())
+
+	_, err := w.Write([]byte("more"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+// runtimeAPI is a local stand-in for the Lambda Runtime API serving a single invocation.
+type runtimeAPI struct {
+	event       string
+	contentType chan string
+	chunks      chan string
+}
+
+func (api *runtimeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/2018-06-01/runtime/invocation/next":
+		if api.event == "" {
+			// block the runtime loop once the single invocation is complete
+			select {}
+		}
+		w.Header().Set("Lambda-Runtime-Aws-Request-Id", "1")
+		w.Header().Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(time.Now().Add(time.Minute).UnixNano()/int64(time.Millisecond), 10))
+		io.WriteString(w, api.event)
+		api.event = ""
+	case "/2018-06-01/runtime/invocation/1/response":
+		api.contentType <- r.Header.Get("Content-Type")
+		for {
+			b := make([]byte, 1024)
+			n, err := r.Body.Read(b)
+			if n > 0 {
+				api.chunks <- string(b[:n])
+			}
+			if err != nil {
+				break
+			}
+		}
+		close(api.chunks)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestStreamingGateway_runtimeAPI(t *testing.T) {
+	api := &runtimeAPI{
+		event:       `{"version": "2.0", "rawPath": "/", "requestContext": {"http": {"method": "GET"}}}`,
+		contentType: make(chan string, 1),
+		chunks:      make(chan string, 10),
+	}
+
+	// the server is not closed as the runtime loop blocks on its next invocation
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := &httptest.Server{Listener: l, Config: &http.Server{Handler: api}}
+	srv.Start()
+	os.Setenv("AWS_LAMBDA_RUNTIME_API", l.Addr().String())
+	defer os.Unsetenv("AWS_LAMBDA_RUNTIME_API")
+
+	proceed := make(chan struct{})
+	gw := NewStreamingGateway(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "first")
+		w.(http.Flusher).Flush()
+		<-proceed
+		fmt.Fprint(w, "second")
+	}))
+
+	go lambda.Start(gw.Invoke)
+
+	assert.Equal(t, streamingContentType, <-api.contentType)
+
+	// the flushed output arrives while the handler is still running
+	first := <-api.chunks
+	assert.Equal(t, `{"statusCode":200,"headers":{"Content-Type":"text/plain; charset=utf8"}}`+"\x00\x00\x00\x00\x00\x00\x00\x00first", first)
+
+	close(proceed)
+
+	var rest bytes.Buffer
+	for chunk := range api.chunks {
+		rest.WriteString(chunk)
+	}
+	assert.Equal(t, "second", rest.String())
+}
@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultIntegrationTimeout is the maximum integration timeout of API Gateway.
+const DefaultIntegrationTimeout = 29 * time.Second
+
+// Timeout configures the request deadline derived from the invocation deadline.
+type Timeout struct {
+	// Margin is subtracted from the Lambda deadline, leaving time to send the response, defaults to 1s.
+	Margin time.Duration
+
+	// Max caps the request duration, defaults to DefaultIntegrationTimeout less the margin,
+	// use a larger value for load balancers and Function URLs.
+	Max time.Duration
+
+	// Status is the status code responded with when the handler has not
+	// finished by the deadline, defaults to 504 Gateway Timeout.
+	Status int
+}
+
+// WithTimeout sets a request context deadline derived from the Lambda deadline and the
+// integration timeout, responding with a 504 Gateway Timeout if the handler has not
+// finished by then instead of letting the invocation time out.
+func WithTimeout(t Timeout) Option {
+	if t.Margin == 0 {
+		t.Margin = time.Second
+	}
+
+	if t.Max == 0 {
+		t.Max = DefaultIntegrationTimeout - t.Margin
+	}
+
+	if t.Status == 0 {
+		t.Status = http.StatusGatewayTimeout
+	}
+
+	return func(c *config) {
+		c.timeout = &t
+	}
+}
+
+// deadline returns the request deadline for the invocation context.
+func (t *Timeout) deadline(ctx context.Context) time.Time {
+	d := time.Now().Add(t.Max)
+	if lambda, ok := ctx.Deadline(); ok && lambda.Add(-t.Margin).Before(d) {
+		d = lambda.Add(-t.Margin)
+	}
+
+	return d
+}
+
+// serve calls the handler, responding with the timeout status
+// if it has not returned by the request deadline.
+func (c *config) serve(h http.Handler, w *ResponseWriter, r *http.Request) {
+	if c.timeout == nil {
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithDeadline(r.Context(), c.timeout.deadline(r.Context()))
+	defer cancel()
+
+	tw := &timeoutWriter{w: w}
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				panicked <- v
+			}
+		}()
+
+		h.ServeHTTP(tw, r.WithContext(ctx))
+		tw.finish()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case v := <-panicked:
+		panic(v)
+	case <-ctx.Done():
+		tw.timeout(c.timeout.Status)
+	}
+}
+
+// timeoutWriter guards the response writer, discarding output written after the deadline.
+type timeoutWriter struct {
+	w        *ResponseWriter
+	mu       sync.Mutex
+	finished bool
+	timedOut bool
+	discard  http.Header
+}
+
+// finish marks the handler as returned, its response is then kept
+// even when it finished after the deadline.
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.finished = true
+}
+
+// timeout replaces the response with the status unless the handler has returned,
+// output written by the handler afterwards is discarded.
+func (tw *timeoutWriter) timeout(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.finished {
+		return
+	}
+
+	tw.timedOut = true
+	tw.w.reset()
+	tw.w.WriteHeader(status)
+	fmt.Fprintln(tw.w, http.StatusText(status))
+}
+
+// Header implementation.
+func (tw *timeoutWriter) Header() http.Header {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		if tw.discard == nil {
+			tw.discard = make(http.Header)
+		}
+		return tw.discard
+	}
+
+	return tw.w.Header()
+}
+
+// Write implementation.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	return tw.w.Write(b)
+}
+
+// WriteHeader implementation.
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.timedOut {
+		tw.w.WriteHeader(status)
+	}
+}
+
+// CloseNotify implementation.
+func (tw *timeoutWriter) CloseNotify() <-chan bool {
+	return tw.w.CloseNotify()
+}
+
+// reset discards the buffered response.
+func (w *ResponseWriter) reset() {
+	w.header = nil
+	w.buf.Reset()
+	w.wroteHeader = false
+	w.out.StatusCode = 0
+}
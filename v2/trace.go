@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// WithTraceContext translates between the X-Amzn-Trace-Id and W3C traceparent header
+// fields, so handlers instrumented with either X-Ray or OpenTelemetry join the trace
+// of the invocation. The X-Ray trace header takes precedence when both are present.
+func WithTraceContext() Option {
+	return func(c *config) {
+		c.traceContext = true
+	}
+}
+
+// TraceID returns the X-Ray trace header of the request stored in ctx, such as
+// "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+func TraceID(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(traceContextKey).(string)
+	return v, ok
+}
+
+// TraceparentFromXRay returns the W3C traceparent header field value equivalent to
+// the X-Ray trace header, ok is false when it has no valid root and parent.
+func TraceparentFromXRay(header string) (string, bool) {
+	var root, parent, sampled string
+
+	for _, field := range strings.Split(header, ";") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "Root":
+			root = kv[1]
+		case "Parent":
+			parent = kv[1]
+		case "Sampled":
+			sampled = kv[1]
+		}
+	}
+
+	parts := strings.Split(root, "-")
+	if len(parts) != 3 || parts[0] != "1" || len(parts[1]) != 8 || len(parts[2]) != 24 {
+		return "", false
+	}
+
+	id := strings.ToLower(parts[1] + parts[2])
+	parent = strings.ToLower(parent)
+	if !isTraceHex(id) || !isTraceHex(parent) || len(parent) != 16 {
+		return "", false
+	}
+
+	flags := "00"
+	if sampled == "1" {
+		flags = "01"
+	}
+
+	return "00-" + id + "-" + parent + "-" + flags, true
+}
+
+// XRayFromTraceparent returns the X-Ray trace header equivalent to the
+// W3C traceparent header field value, ok is false when it is invalid.
+func XRayFromTraceparent(traceparent string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", false
+	}
+
+	id, parent, flags := parts[1], parts[2], parts[3]
+	if len(id) != 32 || len(parent) != 16 || len(flags) != 2 {
+		return "", false
+	}
+
+	if !isTraceHex(id) || !isTraceHex(parent) || !isHex(flags) {
+		return "", false
+	}
+
+	sampled := "0"
+	if strings.IndexByte("13579bdf", flags[1]) >= 0 {
+		sampled = "1"
+	}
+
+	return "Root=1-" + id[:8] + "-" + id[8:] + ";Parent=" + parent + ";Sampled=" + sampled, true
+}
+
+// isTraceHex returns true if s is a lowercase hex trace or span id, which must not be all zeros.
+func isTraceHex(s string) bool {
+	return isHex(s) && strings.Trim(s, "0") != ""
+}
+
+// isHex returns true if s is lowercase hex.
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// setTraceID sets the X-Ray trace header of the invocation on the request and its context,
+// falling back to the header field sent by the client.
+func setTraceID(req *http.Request) *http.Request {
+	id, _ := req.Context().Value("x-amzn-trace-id").(string)
+	if id == "" {
+		id = os.Getenv("_X_AMZN_TRACE_ID")
+	}
+
+	if id == "" {
+		id = req.Header.Get("X-Amzn-Trace-Id")
+	} else {
+		req.Header.Set("X-Amzn-Trace-Id", id)
+	}
+
+	if id == "" {
+		return req
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), traceContextKey, id))
+}
+
+// translateTrace returns the request with its trace header fields translated, when configured.
+func (c *config) translateTrace(r *http.Request) *http.Request {
+	if !c.traceContext {
+		return r
+	}
+
+	if id := r.Header.Get("X-Amzn-Trace-Id"); id != "" {
+		if tp, ok := TraceparentFromXRay(id); ok {
+			r.Header.Set("Traceparent", tp)
+		}
+		return r
+	}
+
+	if id, ok := XRayFromTraceparent(r.Header.Get("Traceparent")); ok {
+		r.Header.Set("X-Amzn-Trace-Id", id)
+		return r.WithContext(context.WithValue(r.Context(), traceContextKey, id))
+	}
+
+	return r
+}
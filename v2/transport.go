@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
+)
+
+// EventFormat is the event format requests are encoded as by a Transport.
+type EventFormat int
+
+const (
+	// FormatHTTP encodes requests as HTTP API 2.0 payload format events.
+	FormatHTTP EventFormat = iota
+
+	// FormatProxy encodes requests as REST API and HTTP API 1.0 payload format events.
+	FormatProxy
+
+	// FormatALB encodes requests as Application Load Balancer events.
+	FormatALB
+
+	// FormatALBMultiValue encodes requests as Application Load Balancer events
+	// of a target group with multi-value headers enabled.
+	FormatALBMultiValue
+
+	// FormatFunctionURL encodes requests as Lambda Function URL events.
+	FormatFunctionURL
+)
+
+// Transport is an http.RoundTripper invoking a gateway in-process, so an ordinary
+// http.Client exercises the event encoding used in production. Each request is
+// encoded as an event of the Format, and the response payload decoded.
+//
+// Failures of the payloads themselves, such as payloads exceeding the Lambda limit
+// or which are not valid JSON, are returned as errors rather than responses.
+type Transport struct {
+	// Gateway is invoked for each request, such as a Gateway or Server.
+	Gateway lambda.Handler
+
+	// Format is the event format of requests, FormatHTTP by default.
+	Format EventFormat
+
+	// MaxPayload is the maximum size of the request and response payloads, defaults to
+	// DefaultMaxPayload, or DefaultMaxALBPayload for the load balancer formats.
+	MaxPayload int
+}
+
+// RoundTrip implementation.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Body != nil {
+		defer r.Body.Close()
+	}
+
+	event, err := t.event(r)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding event")
+	}
+
+	max := t.MaxPayload
+	if max <= 0 {
+		max = DefaultMaxPayload
+		if t.Format == FormatALB || t.Format == FormatALBMultiValue {
+			max = DefaultMaxALBPayload
+		}
+	}
+
+	if len(payload) > max {
+		return nil, errors.Errorf("request payload of %d bytes exceeds the limit of %d bytes", len(payload), max)
+	}
+
+	out, err := t.Gateway.Invoke(r.Context(), payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "invoking gateway")
+	}
+
+	if len(out) > max {
+		return nil, errors.Errorf("response payload of %d bytes exceeds the limit of %d bytes", len(out), max)
+	}
+
+	res, err := t.response(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 100 || res.StatusCode > 999 {
+		return nil, errors.Errorf("malformed response payload: invalid status code %d", res.StatusCode)
+	}
+
+	res.Request = r
+	return res, nil
+}
+
+// event returns the event for the request in the Format.
+func (t *Transport) event(r *http.Request) (interface{}, error) {
+	switch t.Format {
+	case FormatHTTP:
+		return EventFromRequest(r)
+	case FormatProxy:
+		return ProxyEventFromRequest(r)
+	case FormatALB, FormatALBMultiValue:
+		return ALBEventFromRequest(r, t.Format == FormatALBMultiValue)
+	case FormatFunctionURL:
+		return FunctionURLEventFromRequest(r)
+	default:
+		return nil, errors.Errorf("unsupported event format %d", t.Format)
+	}
+}
+
+// response returns the response decoded from the payload in the Format.
+func (t *Transport) response(payload []byte) (*http.Response, error) {
+	switch t.Format {
+	case FormatProxy:
+		var e events.APIGatewayProxyResponse
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "decoding response payload")
+		}
+		return ProxyResponseFromEvent(e)
+	case FormatALB, FormatALBMultiValue:
+		var e events.ALBTargetGroupResponse
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "decoding response payload")
+		}
+		return ALBResponseFromEvent(e)
+	case FormatFunctionURL:
+		var e events.LambdaFunctionURLResponse
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "decoding response payload")
+		}
+		return FunctionURLResponseFromEvent(e)
+	default:
+		var e events.APIGatewayV2HTTPResponse
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, errors.Wrap(err, "decoding response payload")
+		}
+		return ResponseFromEvent(e)
+	}
+}
@@ -0,0 +1,162 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/apex/gateway/v2"
+	"github.com/tj/assert"
+)
+
+// invokerFunc is a lambda.Handler returning a fixed payload or error.
+type invokerFunc func(context.Context, []byte) ([]byte, error)
+
+// Invoke implementation.
+func (f invokerFunc) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return f(ctx, payload)
+}
+
+func TestTransport(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		c, _ := r.Cookie("session")
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("X-URL", r.URL.String())
+		w.Header().Set("X-Session", c.Value)
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(b)
+	})
+
+	formats := []struct {
+		name   string
+		format gateway.EventFormat
+	}{
+		{"http", gateway.FormatHTTP},
+		{"proxy", gateway.FormatProxy},
+		{"alb", gateway.FormatALB},
+		{"alb multi-value", gateway.FormatALBMultiValue},
+		{"function url", gateway.FormatFunctionURL},
+	}
+
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: &gateway.Transport{Gateway: gateway.NewGateway(h), Format: f.format},
+			}
+
+			req, err := http.NewRequest("PUT", "https://example.com/pets?name=Tobi", strings.NewReader("\x00\xff"))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/octet-stream")
+			req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+			res, err := client.Do(req)
+			assert.NoError(t, err)
+			defer res.Body.Close()
+
+			b, err := ioutil.ReadAll(res.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusAccepted, res.StatusCode)
+			assert.Equal(t, "\x00\xff", string(b))
+			assert.Equal(t, "https://example.com/pets?name=Tobi", res.Header.Get("X-URL"))
+			assert.Equal(t, "abc", res.Header.Get("X-Session"))
+			assert.Equal(t, "a=1", res.Cookies()[0].String())
+			assert.Equal(t, req, res.Request)
+		})
+	}
+}
+
+func TestTransport_functionURL(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := gateway.FunctionURLRequestContext(r.Context())
+		if !ok {
+			http.Error(w, "not a function url event", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(c.DomainName + " " + r.Host))
+	})
+
+	client := &http.Client{
+		Transport: &gateway.Transport{Gateway: gateway.NewGateway(h), Format: gateway.FormatFunctionURL},
+	}
+
+	res, err := client.Get("http://example.com/pets")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, gateway.FunctionURLDomain+" example.com", string(b))
+}
+
+func TestTransport_errors(t *testing.T) {
+	cases := []struct {
+		name      string
+		transport *gateway.Transport
+		err       string
+	}{
+		{
+			"request too large",
+			&gateway.Transport{Gateway: gateway.NewGateway(http.NotFoundHandler()), MaxPayload: 16},
+			"request payload of",
+		},
+		{
+			"response too large",
+			&gateway.Transport{Gateway: invokerFunc(func(context.Context, []byte) ([]byte, error) {
+				return []byte(`{"statusCode": 200, "body": "` + strings.Repeat("a", 1024) + `"}`), nil
+			}), MaxPayload: 1024},
+			"response payload of",
+		},
+		{
+			"alb response too large",
+			&gateway.Transport{Gateway: invokerFunc(func(context.Context, []byte) ([]byte, error) {
+				return []byte(`{"statusCode": 200, "body": "` + strings.Repeat("a", gateway.DefaultMaxALBPayload) + `"}`), nil
+			}), Format: gateway.FormatALB},
+			"response payload of",
+		},
+		{
+			"invalid json",
+			&gateway.Transport{Gateway: invokerFunc(func(context.Context, []byte) ([]byte, error) {
+				return []byte(`{"statusCode":`), nil
+			})},
+			"decoding response payload: unexpected end of JSON input",
+		},
+		{
+			"missing status",
+			&gateway.Transport{Gateway: invokerFunc(func(context.Context, []byte) ([]byte, error) {
+				return []byte(`{}`), nil
+			})},
+			"malformed response payload: invalid status code 0",
+		},
+		{
+			"invoke error",
+			&gateway.Transport{Gateway: invokerFunc(func(context.Context, []byte) ([]byte, error) {
+				return nil, errors.New("boom")
+			})},
+			"invoking gateway: boom",
+		},
+		{
+			"unsupported format",
+			&gateway.Transport{Gateway: gateway.NewGateway(http.NotFoundHandler()), Format: 42},
+			"unsupported event format 42",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "https://example.com/pets", nil)
+			assert.NoError(t, err)
+
+			_, err = c.transport.RoundTrip(req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// ErrConnectionGone is returned when posting to a WebSocket connection which is no longer connected.
+var ErrConnectionGone = errors.New("connection gone")
+
+// WebSocketHandler handles the lifecycle of API Gateway WebSocket API connections.
+//
+// Returning an error from Connect rejects the connection, errors implementing
+// StatusCode() int control the status code returned to API Gateway, others respond with a 500.
+type WebSocketHandler interface {
+	// Connect is called for the $connect route.
+	Connect(ctx context.Context, c *WebSocketConn) error
+
+	// Message is called for $default and any custom routes.
+	Message(ctx context.Context, c *WebSocketConn, data []byte) error
+
+	// Disconnect is called for the $disconnect route, it's best-effort and may not be called.
+	Disconnect(ctx context.Context, c *WebSocketConn) error
+}
+
+// WebSocketClient posts data to connected clients, typically backed by
+// the API Gateway Management API PostToConnection action.
+type WebSocketClient interface {
+	PostToConnection(ctx context.Context, connectionID string, data []byte) error
+}
+
+// WebSocketConn is the connection an event was received on.
+type WebSocketConn struct {
+	// ID is the API Gateway connection id.
+	ID string
+
+	// RouteKey is the route selected for the event, such as $connect or $default.
+	RouteKey string
+
+	// Header and Query are only sent by API Gateway on $connect.
+	Header http.Header
+	Query  url.Values
+
+	// RequestContext is the raw API Gateway request context.
+	RequestContext events.APIGatewayWebsocketProxyRequestContext
+
+	client WebSocketClient
+}
+
+// Send posts data to the connection using the gateway's WebSocketClient.
+func (c *WebSocketConn) Send(ctx context.Context, data []byte) error {
+	if c.client == nil {
+		return errors.New("no websocket client configured")
+	}
+
+	return c.client.PostToConnection(ctx, c.ID, data)
+}
+
+// NewWebSocketGateway creates a gateway for WebSocket APIs using the provided handler,
+// the client is optional and used by WebSocketConn.Send.
+func NewWebSocketGateway(h WebSocketHandler, c WebSocketClient) *WebSocketGateway {
+	return &WebSocketGateway{h: h, c: c}
+}
+
+// WebSocketGateway wrap a WebSocketHandler to enable use as a lambda.Handler.
+type WebSocketGateway struct {
+	h WebSocketHandler
+	c WebSocketClient
+}
+
+// Invoke Handler implementation
+func (gw *WebSocketGateway) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	var evt events.APIGatewayWebsocketProxyRequest
+
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return []byte{}, err
+	}
+
+	resp, err := gw.handle(ctx, evt)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return json.Marshal(&resp)
+}
+
+// handle dispatches the event to the handler method matching its route.
+func (gw *WebSocketGateway) handle(ctx context.Context, e events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	c := newWebSocketConn(e, gw.c)
+
+	var err error
+	switch websocketEventType(e) {
+	case "CONNECT":
+		err = gw.h.Connect(ctx, c)
+	case "DISCONNECT":
+		err = gw.h.Disconnect(ctx, c)
+	default:
+		data := []byte(e.Body)
+		if e.IsBase64Encoded {
+			data, err = base64.StdEncoding.DecodeString(e.Body)
+			if err != nil {
+				return events.APIGatewayProxyResponse{}, errors.Wrap(err, "decoding base64 body")
+			}
+		}
+		err = gw.h.Message(ctx, c, data)
+	}
+
+	return websocketResponse(err), nil
+}
+
+// websocketEventType returns the event type, falling back to the route key.
+func websocketEventType(e events.APIGatewayWebsocketProxyRequest) string {
+	if e.RequestContext.EventType != "" {
+		return e.RequestContext.EventType
+	}
+
+	switch e.RequestContext.RouteKey {
+	case "$connect":
+		return "CONNECT"
+	case "$disconnect":
+		return "DISCONNECT"
+	default:
+		return "MESSAGE"
+	}
+}
+
+// newWebSocketConn returns the connection for the given event.
+func newWebSocketConn(e events.APIGatewayWebsocketProxyRequest, client WebSocketClient) *WebSocketConn {
+	c := &WebSocketConn{
+		ID:             e.RequestContext.ConnectionID,
+		RouteKey:       e.RequestContext.RouteKey,
+		Header:         make(http.Header),
+		Query:          make(url.Values),
+		RequestContext: e.RequestContext,
+		client:         client,
+	}
+
+	for k, v := range e.Headers {
+		c.Header.Set(k, v)
+	}
+
+	for k, values := range e.MultiValueHeaders {
+		c.Header[http.CanonicalHeaderKey(k)] = values
+	}
+
+	for k, v := range e.QueryStringParameters {
+		c.Query.Set(k, v)
+	}
+
+	for k, values := range e.MultiValueQueryStringParameters {
+		c.Query[k] = values
+	}
+
+	return c
+}
+
+// websocketResponse returns the integration response for the handler error.
+func websocketResponse(err error) events.APIGatewayProxyResponse {
+	if err == nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
+	}
+
+	status := http.StatusInternalServerError
+	if e, ok := errors.Cause(err).(interface{ StatusCode() int }); ok {
+		status = e.StatusCode()
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       http.StatusText(status),
+	}
+}
+
+// MemoryWebSocketClient is an in-memory WebSocketClient for use in tests.
+type MemoryWebSocketClient struct {
+	mu       sync.Mutex
+	messages map[string][][]byte
+	gone     map[string]bool
+}
+
+// NewMemoryWebSocketClient returns a new in-memory client.
+func NewMemoryWebSocketClient() *MemoryWebSocketClient {
+	return &MemoryWebSocketClient{
+		messages: make(map[string][][]byte),
+		gone:     make(map[string]bool),
+	}
+}
+
+// PostToConnection implementation.
+func (c *MemoryWebSocketClient) PostToConnection(ctx context.Context, connectionID string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gone[connectionID] {
+		return ErrConnectionGone
+	}
+
+	c.messages[connectionID] = append(c.messages[connectionID], append([]byte(nil), data...))
+	return nil
+}
+
+// Close marks the connection as gone, further posts return ErrConnectionGone.
+func (c *MemoryWebSocketClient) Close(connectionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gone[connectionID] = true
+}
+
+// Messages returns the data posted to the connection.
+func (c *MemoryWebSocketClient) Messages(connectionID string) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.messages[connectionID]
+}
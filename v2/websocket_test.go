@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+type statusError int
+
+func (s statusError) Error() string   { return "status error" }
+func (s statusError) StatusCode() int { return int(s) }
+
+type echoHandler struct {
+	connected    []string
+	disconnected []string
+}
+
+func (h *echoHandler) Connect(ctx context.Context, c *WebSocketConn) error {
+	if c.Query.Get("token") != "secret" {
+		return statusError(403)
+	}
+	h.connected = append(h.connected, c.ID+" "+c.Header.Get("Origin"))
+	return nil
+}
+
+func (h *echoHandler) Message(ctx context.Context, c *WebSocketConn, data []byte) error {
+	return c.Send(ctx, append([]byte(c.RouteKey+": "), data...))
+}
+
+func (h *echoHandler) Disconnect(ctx context.Context, c *WebSocketConn) error {
+	h.disconnected = append(h.disconnected, c.ID)
+	return nil
+}
+
+func TestWebSocketGateway_Invoke(t *testing.T) {
+	h := &echoHandler{}
+	client := NewMemoryWebSocketClient()
+	gw := NewWebSocketGateway(h, client)
+	ctx := context.Background()
+
+	payload, err := gw.Invoke(ctx, []byte(`{"headers": {"Origin": "https://example.com"}, "queryStringParameters": {"token": "secret"}, "requestContext": {"routeKey": "$connect", "eventType": "CONNECT", "connectionId": "abc="}}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"statusCode": 200, "headers": null, "multiValueHeaders": null, "body": ""}`, string(payload))
+	assert.Equal(t, []string{"abc= https://example.com"}, h.connected)
+
+	payload, err = gw.Invoke(ctx, []byte(`{"requestContext": {"routeKey": "$connect", "eventType": "CONNECT", "connectionId": "def="}}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"statusCode": 403, "headers": null, "multiValueHeaders": null, "body": "Forbidden"}`, string(payload))
+
+	_, err = gw.Invoke(ctx, []byte(`{"body": "hello", "requestContext": {"routeKey": "$default", "eventType": "MESSAGE", "connectionId": "abc="}}`))
+	assert.NoError(t, err)
+
+	_, err = gw.Invoke(ctx, []byte(`{"body": "aGk=", "isBase64Encoded": true, "requestContext": {"routeKey": "sendmessage", "connectionId": "abc="}}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][]byte{[]byte("$default: hello"), []byte("sendmessage: hi")}, client.Messages("abc="))
+
+	_, err = gw.Invoke(ctx, []byte(`{"requestContext": {"routeKey": "$disconnect", "connectionId": "abc="}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc="}, h.disconnected)
+}
+
+func TestWebSocketGateway_Invoke_gone(t *testing.T) {
+	client := NewMemoryWebSocketClient()
+	client.Close("abc=")
+	gw := NewWebSocketGateway(&echoHandler{}, client)
+
+	payload, err := gw.Invoke(context.Background(), []byte(`{"body": "hello", "requestContext": {"routeKey": "$default", "connectionId": "abc="}}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"statusCode": 500, "headers": null, "multiValueHeaders": null, "body": "Internal Server Error"}`, string(payload))
+}
+
+func TestWebSocketConn_Send_noClient(t *testing.T) {
+	c := &WebSocketConn{ID: "abc="}
+	assert.EqualError(t, c.Send(context.Background(), []byte("hi")), "no websocket client configured")
+}